@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/services"
+)
+
+// runCommand 执行子命令：import、export、restore、generate、embed、analyze
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(args)
+	case "export":
+		return runExport(args)
+	case "restore":
+		return runRestore(args)
+	case "generate":
+		return runGenerate(args)
+	case "embed":
+		return runEmbed(args)
+	case "analyze":
+		return runAnalyze()
+	default:
+		return fmt.Errorf("未知命令: %s", name)
+	}
+}
+
+// runImport 导入 WordPress WXR 文件或 Hexo/Hugo Markdown 文件
+// 用法: server import -format wxr|markdown -path <文件、zip 压缩包或目录> [-author 用户ID] [-dry-run]
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "导入格式：wxr 或 markdown")
+	source := flags.String("path", "", "WXR 文件，或 Markdown 文件、zip 压缩包、目录")
+	authorID := flags.Uint("author", 0, "文章作者的用户ID，默认为第一个用户")
+	dryRun := flags.Bool("dry-run", false, "仅预览导入结果，不写入数据库")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *source == "" {
+		return errors.New("请通过 -path 指定导入文件或目录")
+	}
+
+	if *authorID == 0 {
+		var user models.User
+		if err := database.DB.Order("id ASC").First(&user).Error; err != nil {
+			return errors.New("没有可用的作者，请先创建用户")
+		}
+		*authorID = user.ID
+	}
+
+	info, err := os.Stat(*source)
+	if err != nil {
+		return err
+	}
+
+	var data *services.ImportData
+	if info.IsDir() {
+		if *format != services.ImportFormatMarkdown {
+			return errors.New("目录仅支持 markdown 格式")
+		}
+		data, err = services.ParseMarkdownFiles(os.DirFS(*source))
+	} else {
+		var content []byte
+		content, err = os.ReadFile(*source)
+		if err == nil {
+			data, err = services.ParseImportFile(*format, *source, content)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := services.Import(data, services.ImportOptions{
+		AuthorID: uint(*authorID),
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	printImportReport(report)
+	return nil
+}
+
+// printImportReport 输出导入结果
+func printImportReport(report *services.ImportReport) {
+	if report.DryRun {
+		fmt.Println("预览模式，未写入数据库")
+	}
+	fmt.Printf("文章: %d（已发布 %d，草稿 %d）\n", report.Articles, report.Published, report.Drafts)
+	fmt.Printf("评论: %d\n", report.Comments)
+	fmt.Printf("新建分类: %d %s\n", len(report.CreatedCategories), strings.Join(report.CreatedCategories, ", "))
+	fmt.Printf("新建标签: %d %s\n", len(report.CreatedTags), strings.Join(report.CreatedTags, ", "))
+	if len(report.Skipped) > 0 {
+		fmt.Printf("已存在而跳过: %d\n", len(report.Skipped))
+		for _, title := range report.Skipped {
+			fmt.Printf("  - %s\n", title)
+		}
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("警告: %s\n", warning)
+	}
+}
+
+// runExport 导出全站备份
+// 用法: server export [-output backup.zip] [-format json|markdown]
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "备份文件路径，默认为 backup-<时间>.zip")
+	format := flags.String("format", services.BackupFormatJSON, "文章格式：json 或 markdown")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("backup-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	manifest, err := services.ExportBackup(file, *format)
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+
+	fmt.Printf("备份已保存到 %s\n", *output)
+	printBackupManifest(manifest)
+	return nil
+}
+
+// runRestore 从备份恢复到空数据库
+// 用法: server restore -input backup.zip
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	input := flags.String("input", "", "备份文件路径")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return errors.New("请通过 -input 指定备份文件")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	manifest, err := services.RestoreBackup(file, info.Size())
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("恢复完成")
+	printBackupManifest(manifest)
+	return nil
+}
+
+// printBackupManifest 输出备份内容统计
+func printBackupManifest(manifest *services.BackupManifest) {
+	fmt.Printf("用户: %d，分类: %d，标签: %d，系列: %d\n", manifest.Users, manifest.Categories, manifest.Tags, manifest.Series)
+	fmt.Printf("文章: %d，评论: %d，设置: %d，媒体文件: %d\n", manifest.Articles, manifest.Comments, manifest.Settings, manifest.MediaFiles)
+}
+
+// runGenerate 生成静态站点，默认只重新渲染有更新的文章
+// 用法: server generate [-output public] [-full]
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	output := flags.String("output", "public", "输出目录")
+	full := flags.Bool("full", false, "忽略上次生成的结果，全部重新生成")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := services.GenerateSite(services.GenerateOptions{
+		OutputDir: *output,
+		Full:      *full,
+	})
+	if err != nil {
+		return err
+	}
+
+	if report.FullRebuild {
+		fmt.Println("已全部重新生成")
+	}
+	fmt.Printf("站点已生成到 %s\n", report.OutputDir)
+	fmt.Printf("文章: 渲染 %d，未变化 %d，删除 %d\n", report.Rendered, report.Unchanged, report.Removed)
+	fmt.Printf("首页分页: %d，分类页: %d，标签页: %d\n", report.Pages, report.Categories, report.Tags)
+	return nil
+}
+
+// runEmbed 为已发布文章计算语义搜索使用的向量，默认只计算新增和内容变化的文章
+// 用法: server embed [-full]
+func runEmbed(args []string) error {
+	flags := flag.NewFlagSet("embed", flag.ContinueOnError)
+	full := flags.Bool("full", false, "忽略已有结果，全部重新计算（如更换模型后）")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := services.InitEmbedding(config.AppConfig.AI); err != nil {
+		return err
+	}
+	if !services.EmbeddingEnabled() {
+		return errors.New("未配置向量模型，请设置 ai.embedding.provider")
+	}
+
+	report, err := services.ReindexEmbeddings(context.Background(), *full)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("向量: 计算 %d，未变化 %d，删除 %d\n", report.Indexed, report.Unchanged, report.Removed)
+	fmt.Printf("问答分段: 重新分段 %d 篇\n", report.Chunked)
+	for _, failure := range report.Failed {
+		fmt.Printf("失败: %s\n", failure)
+	}
+	return nil
+}
+
+// runAnalyze 重新计算所有文章的目录、字数和阅读时长，用于回填已有文章
+// 用法: server analyze
+func runAnalyze() error {
+	updated, err := services.RefreshArticleMeta()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已更新 %d 篇文章的目录和字数\n", updated)
+	return nil
+}
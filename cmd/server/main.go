@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"go-blog/internal/config"
 	"go-blog/internal/database"
+	"go-blog/internal/events"
 	"go-blog/internal/handlers"
 	"go-blog/internal/router"
+	"go-blog/internal/services"
 	"go-blog/pkg/utils"
 	"go-blog/web"
 
@@ -39,19 +48,78 @@ func main() {
 		log.Fatalf("种子数据初始化失败: %v", err)
 	}
 
+	// 执行子命令（如 import），完成后退出
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("执行命令失败: %v", err)
+		}
+		return
+	}
+
 	// 设置Gin模式
 	gin.SetMode(config.AppConfig.Server.Mode)
 
 	// 初始化AI服务
-	handlers.InitAIService()
+	if err := handlers.InitAIService(); err != nil {
+		log.Fatalf("AI服务初始化失败: %v", err)
+	}
+	if err := services.InitEmbedding(config.AppConfig.AI); err != nil {
+		log.Fatalf("向量模型初始化失败: %v", err)
+	}
+
+	// 初始化浏览量计数器和访问统计
+	services.InitViewCounter()
+	services.InitAnalytics()
+
+	// 初始化邮件服务和订阅推送
+	services.InitMailer()
+	services.InitNewsletter()
+
+	// 初始化 Webhook 投递
+	services.InitWebhooks()
+
+	// 启动事件总线并注册订阅者
+	services.RegisterEventHandlers()
+	events.Init()
 
 	// 设置路由，传入 SPA handler（嵌入的前端静态文件）
 	r := router.SetupRouter(web.ServeSPA())
 
 	// 启动服务器
 	addr := fmt.Sprintf(":%d", config.AppConfig.Server.Port)
-	log.Printf("服务器启动在端口 %s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("服务器启动失败: %v", err)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: r,
 	}
+
+	go func() {
+		log.Printf("服务器启动在端口 %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("服务器启动失败: %v", err)
+		}
+	}()
+
+	// 等待退出信号，优雅关闭
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("正在关闭服务器...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("服务器关闭异常: %v", err)
+	}
+
+	// 等待异步事件处理完成，之后再停止邮件和 Webhook 等后台任务
+	events.Shutdown()
+
+	// 写入尚未保存的浏览量和访问统计
+	services.StopViewCounter()
+	services.StopAnalytics()
+	services.StopNewsletter()
+	services.StopWebhooks()
+	services.StopMailer()
+
+	log.Println("服务器已关闭")
 }
@@ -8,16 +8,27 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	CORS     CORSConfig     `mapstructure:"cors"`
-	AI       AIConfig       `mapstructure:"ai"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	CORS       CORSConfig       `mapstructure:"cors"`
+	AI         AIConfig         `mapstructure:"ai"`
+	Summary    SummaryConfig    `mapstructure:"summary"`
+	Related    RelatedConfig    `mapstructure:"related"`
+	Views      ViewsConfig      `mapstructure:"views"`
+	Analytics  AnalyticsConfig  `mapstructure:"analytics"`
+	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	Newsletter NewsletterConfig `mapstructure:"newsletter"`
+	Webhook    WebhookConfig    `mapstructure:"webhook"`
+	Events     EventsConfig     `mapstructure:"events"`
+	Backup     BackupConfig     `mapstructure:"backup"`
 }
 
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port    int    `mapstructure:"port"`
+	Mode    string `mapstructure:"mode"`
+	SiteURL string `mapstructure:"site_url"` // 站点访问地址，用于生成邮件等外部链接
 }
 
 type DatabaseConfig struct {
@@ -45,8 +56,15 @@ type CORSConfig struct {
 }
 
 type AIConfig struct {
-	Provider string     `mapstructure:"provider"`
-	Qwen     QwenConfig `mapstructure:"qwen"`
+	Provider    string          `mapstructure:"provider"`     // qwen, openai, ollama, fake
+	IdleTimeout int             `mapstructure:"idle_timeout"` // 上游接口无数据返回的最长等待时间（秒），超时后中止请求
+	Qwen        QwenConfig      `mapstructure:"qwen"`
+	OpenAI      OpenAIConfig    `mapstructure:"openai"`
+	Ollama      OllamaConfig    `mapstructure:"ollama"`
+	Fake        FakeAIConfig    `mapstructure:"fake"`
+	Quota       AIQuotaConfig   `mapstructure:"quota"`
+	Pricing     AIPricingConfig `mapstructure:"pricing"`
+	Embedding   EmbeddingConfig `mapstructure:"embedding"`
 }
 
 type QwenConfig struct {
@@ -57,6 +75,116 @@ type QwenConfig struct {
 	Temperature float64 `mapstructure:"temperature"`
 }
 
+type OpenAIConfig struct {
+	APIKey      string  `mapstructure:"api_key"`
+	APIURL      string  `mapstructure:"api_url"` // 接口根地址，如 https://api.openai.com/v1
+	Model       string  `mapstructure:"model"`
+	MaxTokens   int     `mapstructure:"max_tokens"`
+	Temperature float64 `mapstructure:"temperature"`
+}
+
+type OllamaConfig struct {
+	APIURL      string  `mapstructure:"api_url"` // 默认 http://localhost:11434
+	Model       string  `mapstructure:"model"`
+	MaxTokens   int     `mapstructure:"max_tokens"`
+	Temperature float64 `mapstructure:"temperature"`
+}
+
+type AIQuotaConfig struct {
+	DailyTokens          int64 `mapstructure:"daily_tokens" json:"daily_tokens"`                     // 每个用户每日 token 上限，0 表示不限制
+	MonthlyTokens        int64 `mapstructure:"monthly_tokens" json:"monthly_tokens"`                 // 每个用户每月 token 上限，0 表示不限制
+	AnonymousDailyTokens int64 `mapstructure:"anonymous_daily_tokens" json:"anonymous_daily_tokens"` // 未登录读者（问答、语义搜索）每日共用的 token 上限，0 表示不对未登录读者开放
+}
+
+type AIPricingConfig struct {
+	PromptPrice     float64 `mapstructure:"prompt_price"`     // 每千输入 token 的价格
+	CompletionPrice float64 `mapstructure:"completion_price"` // 每千输出 token 的价格
+	Currency        string  `mapstructure:"currency"`
+}
+
+type FakeAIConfig struct {
+	Response  string `mapstructure:"response"`   // 固定回复，为空时根据提示词生成确定的内容
+	ChunkSize int    `mapstructure:"chunk_size"` // 流式输出每段的字符数
+}
+
+type EmbeddingConfig struct {
+	Provider   string  `mapstructure:"provider"` // qwen, openai, ollama, fake，为空时不启用语义搜索
+	APIKey     string  `mapstructure:"api_key"`  // 为空时使用同名聊天提供方的配置
+	APIURL     string  `mapstructure:"api_url"`
+	Model      string  `mapstructure:"model"`
+	Dimensions int     `mapstructure:"dimensions"` // 向量维度，部分模型支持指定；fake 默认 256
+	BatchSize  int     `mapstructure:"batch_size"` // 批量计算时每次请求的文本数
+	MinScore   float64 `mapstructure:"min_score"`  // 语义搜索结果的最低相似度
+}
+
+type SummaryConfig struct {
+	Mode      string `mapstructure:"mode"` // auto, ai
+	MaxLength int    `mapstructure:"max_length"`
+}
+
+type RelatedConfig struct {
+	Limit       int     `mapstructure:"limit"`
+	TFIDFWeight float64 `mapstructure:"tfidf_weight"` // 内容相似度权重，0 表示不启用
+	CacheTTL    int     `mapstructure:"cache_ttl"`    // 缓存时间（秒）
+}
+
+type ViewsConfig struct {
+	DedupWindow   int `mapstructure:"dedup_window"`   // 同一访客重复访问不计数的时间窗口（秒）
+	FlushInterval int `mapstructure:"flush_interval"` // 浏览量写入数据库的间隔（秒）
+}
+
+type AnalyticsConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	GeoIPFile     string `mapstructure:"geoip_file"`     // 可选的本地 GeoIP CSV 文件
+	FlushInterval int    `mapstructure:"flush_interval"` // 统计数据写入数据库的间隔（秒）
+}
+
+type RateLimitConfig struct {
+	Requests int `mapstructure:"requests"` // 时间窗口内允许的请求数
+	Window   int `mapstructure:"window"`   // 时间窗口（秒）
+}
+
+type MailConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Username     string `mapstructure:"username"`
+	Password     string `mapstructure:"password"`
+	From         string `mapstructure:"from"`
+	FromName     string `mapstructure:"from_name"`
+	TLS          bool   `mapstructure:"tls"`           // 使用SSL/TLS直连（如465端口），否则在服务器支持时使用STARTTLS
+	MaxAttempts  int    `mapstructure:"max_attempts"`  // 最大发送次数
+	PollInterval int    `mapstructure:"poll_interval"` // 发送队列轮询间隔（秒）
+}
+
+type NewsletterConfig struct {
+	Enabled       bool         `mapstructure:"enabled"`
+	SendOnPublish bool         `mapstructure:"send_on_publish"` // 文章发布时推送给订阅者
+	Digest        DigestConfig `mapstructure:"digest"`
+}
+
+type DigestConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Weekday int  `mapstructure:"weekday"` // 0-6，0 表示周日
+	Hour    int  `mapstructure:"hour"`    // 0-23
+}
+
+type WebhookConfig struct {
+	MaxAttempts  int `mapstructure:"max_attempts"`  // 最大投递次数
+	Timeout      int `mapstructure:"timeout"`       // 单次请求超时（秒）
+	PollInterval int `mapstructure:"poll_interval"` // 重试队列轮询间隔（秒）
+}
+
+type EventsConfig struct {
+	Workers      int `mapstructure:"workers"`       // 异步订阅者的处理协程数
+	QueueSize    int `mapstructure:"queue_size"`    // 异步事件队列长度，队列满时在发布方同步执行
+	DrainTimeout int `mapstructure:"drain_timeout"` // 关闭时等待队列处理完成的最长时间（秒）
+}
+
+type BackupConfig struct {
+	MediaDir string `mapstructure:"media_dir"` // 媒体文件目录，设置后随备份一起导出和恢复
+}
+
 var AppConfig *Config
 
 // LoadConfig 加载配置文件
@@ -46,9 +46,21 @@ func AutoMigrate() error {
 		&models.User{},
 		&models.Category{},
 		&models.Tag{},
+		&models.Series{},
 		&models.Article{},
 		&models.Comment{},
 		&models.Setting{},
+		&models.PageViewStat{},
+		&models.Reaction{},
+		&models.MailQueue{},
+		&models.Subscriber{},
+		&models.NewsletterDigest{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.AIUsage{},
+		&models.PromptTemplate{},
+		&models.ArticleEmbedding{},
+		&models.ArticleChunk{},
 	)
 
 	if err != nil {
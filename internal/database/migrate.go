@@ -66,6 +66,7 @@ func SeedData() error {
 		{Key: "posts_per_page", Value: "10"},
 		{Key: "enable_comments", Value: "true"},
 		{Key: "icp_beian", Value: ""},
+		{Key: "default_language", Value: "zh"},
 	}
 
 	for _, setting := range settings {
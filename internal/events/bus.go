@@ -0,0 +1,199 @@
+package events
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go-blog/internal/config"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultQueueSize    = 256
+	defaultDrainTimeout = 10 * time.Second
+)
+
+// Event 领域事件
+type Event struct {
+	Name       string
+	Payload    interface{}
+	OccurredAt time.Time
+}
+
+// Handler 事件处理函数
+type Handler func(Event)
+
+type asyncTask struct {
+	handler Handler
+	event   Event
+}
+
+// Bus 进程内事件总线
+// 同步订阅者在发布方按注册顺序执行；异步订阅者由后台协程执行，
+// 总线未启动、已关闭或队列已满时退化为在发布方同步执行，保证事件不丢失
+type Bus struct {
+	mu      sync.RWMutex
+	sync    map[string][]Handler
+	async   map[string][]Handler
+	queue   chan asyncTask
+	running bool
+	wg      sync.WaitGroup
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		sync:  make(map[string][]Handler),
+		async: make(map[string][]Handler),
+	}
+}
+
+// Subscribe 注册同步订阅者，在发布方执行，适合缓存失效等轻量操作
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync[name] = append(b.sync[name], handler)
+}
+
+// SubscribeAsync 注册异步订阅者，适合发送通知等耗时操作
+func (b *Bus) SubscribeAsync(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[name] = append(b.async[name], handler)
+}
+
+// Start 启动异步处理协程
+func (b *Bus) Start(workers, queueSize int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return
+	}
+
+	b.queue = make(chan asyncTask, queueSize)
+	b.running = true
+	for i := 0; i < workers; i++ {
+		b.wg.Add(1)
+		go b.worker(b.queue)
+	}
+}
+
+// Publish 发布事件
+func (b *Bus) Publish(name string, payload interface{}) {
+	event := Event{
+		Name:       name,
+		Payload:    payload,
+		OccurredAt: time.Now(),
+	}
+
+	b.mu.RLock()
+	syncHandlers := b.sync[name]
+	var overflow []Handler
+	for _, handler := range b.async[name] {
+		if !b.running {
+			overflow = append(overflow, handler)
+			continue
+		}
+		select {
+		case b.queue <- asyncTask{handler: handler, event: event}:
+		default:
+			overflow = append(overflow, handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range syncHandlers {
+		invoke(handler, event)
+	}
+	for _, handler := range overflow {
+		invoke(handler, event)
+	}
+}
+
+// Shutdown 停止接收异步任务，并等待队列中的事件处理完成
+func (b *Bus) Shutdown(timeout time.Duration) error {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return nil
+	}
+	b.running = false
+	close(b.queue)
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("等待事件处理超时")
+	}
+}
+
+// worker 处理异步任务
+func (b *Bus) worker(queue <-chan asyncTask) {
+	defer b.wg.Done()
+	for task := range queue {
+		invoke(task.handler, task.event)
+	}
+}
+
+// invoke 执行订阅者，单个订阅者异常不影响其他订阅者
+func invoke(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("处理事件 %s 异常: %v", event.Name, r)
+		}
+	}()
+	handler(event)
+}
+
+var defaultBus = NewBus()
+
+// Init 按配置启动默认事件总线
+func Init() {
+	cfg := config.AppConfig.Events
+
+	workers := defaultWorkers
+	if cfg.Workers > 0 {
+		workers = cfg.Workers
+	}
+	queueSize := defaultQueueSize
+	if cfg.QueueSize > 0 {
+		queueSize = cfg.QueueSize
+	}
+	defaultBus.Start(workers, queueSize)
+}
+
+// Shutdown 关闭默认事件总线，等待异步事件处理完成
+func Shutdown() {
+	timeout := defaultDrainTimeout
+	if config.AppConfig != nil && config.AppConfig.Events.DrainTimeout > 0 {
+		timeout = time.Duration(config.AppConfig.Events.DrainTimeout) * time.Second
+	}
+	if err := defaultBus.Shutdown(timeout); err != nil {
+		log.Printf("关闭事件总线: %v", err)
+	}
+}
+
+// Subscribe 在默认事件总线上注册同步订阅者
+func Subscribe(name string, handler Handler) {
+	defaultBus.Subscribe(name, handler)
+}
+
+// SubscribeAsync 在默认事件总线上注册异步订阅者
+func SubscribeAsync(name string, handler Handler) {
+	defaultBus.SubscribeAsync(name, handler)
+}
+
+// Publish 在默认事件总线上发布事件
+func Publish(name string, payload interface{}) {
+	defaultBus.Publish(name, payload)
+}
@@ -0,0 +1,32 @@
+package events
+
+import "go-blog/internal/models"
+
+// 领域事件名称
+const (
+	ArticleCreated   = "article.created"
+	ArticleUpdated   = "article.updated"
+	ArticlePublished = "article.published" // 文章首次发布，与 created/updated 一同发布
+	ArticleDeleted   = "article.deleted"
+	CommentCreated   = "comment.created"
+	CommentDeleted   = "comment.deleted"
+	SettingsUpdated  = "settings.updated"
+)
+
+// ArticlePayload 文章事件内容
+type ArticlePayload struct {
+	Article            models.Article
+	Published          bool // 本次操作中首次发布
+	WasPublished       bool // 操作前为已发布状态
+	SummaryRegenerated bool // 本次操作中重新截取了摘要
+}
+
+// CommentPayload 评论事件内容
+type CommentPayload struct {
+	Comment models.Comment
+}
+
+// SettingsPayload 设置变更事件内容
+type SettingsPayload struct {
+	Settings map[string]string // 本次变更的设置项
+}
@@ -1,28 +1,35 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"unicode/utf8"
 
 	"go-blog/internal/config"
 	"go-blog/internal/services"
+	"go-blog/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 var aiService services.AIService
 
-// InitAIService 初始化AI服务
-func InitAIService() {
-	cfg := config.AppConfig.AI.Qwen
-	aiService = services.NewQwenService(
-		cfg.APIKey,
-		cfg.APIURL,
-		cfg.Model,
-		cfg.MaxTokens,
-		cfg.Temperature,
-	)
+// InitAIService 按配置的提供方初始化AI服务
+func InitAIService() error {
+	service, err := services.NewAIService(config.AppConfig.AI)
+	if err != nil {
+		return err
+	}
+	aiService = service
+
+	// 文章发布后异步生成摘要
+	services.SetSummaryAIService(aiService)
+	return nil
 }
 
 // GenerateArticle 生成文章
@@ -39,8 +46,8 @@ func GenerateArticle(c *gin.Context) {
 		return
 	}
 
-	streamResponse(c, func(callback func(string) error) error {
-		return aiService.StreamGenerateArticle(&req, callback)
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		return aiService.StreamGenerateArticle(ctx, &req, callback)
 	})
 }
 
@@ -57,8 +64,8 @@ func ContinueWriting(c *gin.Context) {
 		return
 	}
 
-	streamResponse(c, func(callback func(string) error) error {
-		return aiService.StreamContinueWriting(&req, callback)
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		return aiService.StreamContinueWriting(ctx, &req, callback)
 	})
 }
 
@@ -75,8 +82,8 @@ func PolishArticle(c *gin.Context) {
 		return
 	}
 
-	streamResponse(c, func(callback func(string) error) error {
-		return aiService.StreamPolishArticle(&req, callback)
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		return aiService.StreamPolishArticle(ctx, &req, callback)
 	})
 }
 
@@ -93,33 +100,126 @@ func ExpandOutline(c *gin.Context) {
 		return
 	}
 
-	streamResponse(c, func(callback func(string) error) error {
-		return aiService.StreamExpandOutline(&req, callback)
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		return aiService.StreamExpandOutline(ctx, &req, callback)
+	})
+}
+
+// TranslateArticleRequest 翻译文章请求
+type TranslateArticleRequest struct {
+	ArticleID      uint   `json:"article_id" binding:"required"`
+	TargetLanguage string `json:"target_language" binding:"required"`
+}
+
+// TranslateArticle 流式翻译文章，完成后保存为关联到原文的译文草稿
+// 保存成功时在 [DONE] 之前发送 saved 事件，数据为译文的文章ID
+func TranslateArticle(c *gin.Context) {
+	var req TranslateArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误: " + err.Error()})
+		return
+	}
+
+	job, err := services.PrepareTranslation(req.ArticleID, req.TargetLanguage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		title, content, err := job.Translate(ctx, aiService, callback)
+		if err != nil {
+			return err
+		}
+		article, err := job.Save(title, content, c.GetUint("user_id"))
+		if err != nil {
+			return fmt.Errorf("保存译文失败: %w", err)
+		}
+		return sendEvent(c, "saved", gin.H{"article_id": article.ID, "language": article.Language})
+	})
+}
+
+// AskRequest 问答请求
+type AskRequest struct {
+	Question string `json:"question" binding:"required"`
+	Language string `json:"lang"` // 只检索该语言的文章，为空时不限
+}
+
+// AskBlog 根据博客已发布的文章回答读者的问题
+// 先发送 sources 事件，数据为引用的文章分段（编号与回答中的 [n] 对应），再流式输出回答
+func AskBlog(c *gin.Context) {
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误: " + err.Error()})
+		return
+	}
+
+	if utf8.RuneCountInString(req.Question) > services.AskQuestionMaxLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("问题不能超过%d字", services.AskQuestionMaxLength)})
+		return
+	}
+	if !services.EmbeddingEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrEmbeddingDisabled.Error()})
+		return
+	}
+
+	streamResponse(c, func(ctx context.Context, callback func(string) error) error {
+		return services.AskBlog(ctx, aiService, req.Question, req.Language, func(sources []services.AnswerSource) error {
+			return sendEvent(c, "sources", sources)
+		}, callback)
 	})
 }
 
+// sendEvent 在流式响应中发送自定义事件
+func sendEvent(c *gin.Context, event string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, jsonData); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
 // streamResponse 辅助函数：处理SSE流式响应
-func streamResponse(c *gin.Context, param func(func(string) error) error) {
+// 调用前检查当前用户的用量上限，用量计入当前用户（未登录时计入访客）；浏览器断开连接时请求的 context 被取消，上游模型请求随之中止
+func streamResponse(c *gin.Context, param func(context.Context, func(string) error) error) {
+	userID := c.GetUint("user_id")
+	if !checkAIQuota(c, userID) {
+		return
+	}
+
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
 	c.Writer.Header().Set("Transfer-Encoding", "chunked")
 
-	err := param(func(content string) error {
+	ctx := aiContext(c, userID)
+	err := param(ctx, func(content string) error {
 		data := gin.H{
 			"content": content,
 		}
 		jsonData, _ := json.Marshal(data)
-		fmt.Fprintf(c.Writer, "data: %s\n\n", jsonData)
+		if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", jsonData); err != nil {
+			return err
+		}
 		c.Writer.Flush()
 		return nil
 	})
 
+	if ctx.Err() != nil {
+		// 客户端已断开，无需再写入
+		log.Printf("AI流式响应已中止: %v", ctx.Err())
+		return
+	}
+
 	if err != nil {
-		// Log error
-		fmt.Printf("Stream error: %v\n", err)
+		log.Printf("AI流式响应失败: %v", err)
 		// 发送错误事件
-		fmt.Fprintf(c.Writer, "event: error\ndata: {\"error\": \"%s\"}\n\n", err.Error())
+		errData, _ := json.Marshal(gin.H{"error": err.Error()})
+		fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", errData)
 		c.Writer.Flush()
 	} else {
 		// 发送完成信号
@@ -127,3 +227,81 @@ func streamResponse(c *gin.Context, param func(func(string) error) error) {
 		c.Writer.Flush()
 	}
 }
+
+// SuggestMetadataRequest 推荐元数据请求
+type SuggestMetadataRequest struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// SuggestMetadata 根据草稿推荐标题、摘要、标签和分类
+func SuggestMetadata(c *gin.Context) {
+	var req SuggestMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参数错误: " + err.Error()})
+		return
+	}
+
+	if req.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "内容不能为空"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if !checkAIQuota(c, userID) {
+		return
+	}
+
+	ctx := aiContext(c, userID)
+	result, err := services.SuggestArticleMetadata(ctx, aiService, req.Title, req.Content)
+	if err != nil {
+		log.Printf("推荐元数据失败: %v", err)
+		utils.Error(c, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// checkAIQuota 检查当前用户的AI用量上限，未登录时检查访客共用的上限，超出时返回 429，未对访客开放时返回 401
+func checkAIQuota(c *gin.Context, userID uint) bool {
+	check := func() error { return services.CheckAIQuota(userID) }
+	if userID == 0 {
+		check = services.CheckAnonymousAIQuota
+	}
+	if err := check(); err != nil {
+		if errors.Is(err, services.ErrAIQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return false
+		}
+		if errors.Is(err, services.ErrAnonymousAIDisabled) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查AI用量失败"})
+		return false
+	}
+	return true
+}
+
+// aiContext 请求的 context，用量计入当前用户；未登录时计入访客，不占用系统调用的额度
+func aiContext(c *gin.Context, userID uint) context.Context {
+	if userID == 0 {
+		return services.WithAnonymousAI(c.Request.Context())
+	}
+	return services.WithAIUser(c.Request.Context(), userID)
+}
+
+// GetAIUsage 获取AI用量统计和估算费用
+func GetAIUsage(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	userID, _ := strconv.ParseUint(c.Query("user_id"), 10, 64)
+
+	report, err := services.GetAIUsageReport(days, uint(userID))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, report)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/services"
+	"go-blog/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupAITest 初始化测试数据库和使用模拟提供方的AI服务
+func setupAITest(t *testing.T) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	testutil.SetupDB(t)
+
+	service, err := services.NewAIService(config.AIConfig{Provider: "fake"})
+	if err != nil {
+		t.Fatalf("创建模拟AI服务失败: %v", err)
+	}
+	previous := aiService
+	aiService = service
+	t.Cleanup(func() { aiService = previous })
+}
+
+// newAIRouter 以 userID 身份访问AI接口的路由
+func newAIRouter(userID uint) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	r.POST("/ai/generate", GenerateArticle)
+	r.POST("/ai/suggest-metadata", SuggestMetadata)
+	r.POST("/ai/translate", TranslateArticle)
+	return r
+}
+
+func postJSON(r http.Handler, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// sseEvent SSE 响应中的一个事件
+type sseEvent struct {
+	Name string
+	Data string
+}
+
+func parseSSE(body string) []sseEvent {
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				event.Name = name
+			} else if data, ok := strings.CutPrefix(line, "data: "); ok {
+				event.Data = data
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStreamResponse(t *testing.T) {
+	setupAITest(t)
+	r := newAIRouter(3)
+
+	w := postJSON(r, "/ai/generate", `{"title": "Go 并发"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type 应为 text/event-stream，实际 %s", ct)
+	}
+
+	events := parseSSE(w.Body.String())
+	if len(events) < 2 || events[len(events)-1].Data != "[DONE]" {
+		t.Fatalf("应以 [DONE] 结束: %s", w.Body.String())
+	}
+	var content strings.Builder
+	for _, event := range events[:len(events)-1] {
+		var chunk struct {
+			Content string `json:"content"`
+		}
+		if event.Name != "" || json.Unmarshal([]byte(event.Data), &chunk) != nil {
+			t.Fatalf("无效的数据事件: %+v", event)
+		}
+		content.WriteString(chunk.Content)
+	}
+	if !strings.HasPrefix(content.String(), "这是模拟生成的内容") {
+		t.Errorf("内容不正确: %s", content.String())
+	}
+
+	var usage models.AIUsage
+	if err := database.DB.Where("user_id = ? AND operation = ?", 3, services.AIOperationGenerate).First(&usage).Error; err != nil {
+		t.Errorf("用量应计入当前用户: %v", err)
+	}
+}
+
+func TestStreamResponseQuotaExceeded(t *testing.T) {
+	setupAITest(t)
+	r := newAIRouter(3)
+	config.AppConfig.AI.Quota.DailyTokens = 10
+
+	// 先用掉当日额度
+	ctx := services.WithAIUser(context.Background(), 3)
+	if _, err := aiService.GenerateArticle(ctx, &services.GenerateArticleRequest{Title: "占用额度"}); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+
+	w := postJSON(r, "/ai/generate", `{"title": "Go 并发"}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超出额度应返回 429，实际 %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "data:") {
+		t.Errorf("超出额度时不应开始流式输出: %s", w.Body.String())
+	}
+
+	w = postJSON(r, "/ai/suggest-metadata", `{"content": "草稿"}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("推荐元数据同样受额度限制，实际 %d", w.Code)
+	}
+}
+
+func TestSuggestMetadataHandler(t *testing.T) {
+	setupAITest(t)
+	r := newAIRouter(3)
+
+	w := postJSON(r, "/ai/suggest-metadata", `{"content": "一篇关于 Go 的草稿"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data services.MetadataSuggestionResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if len(resp.Data.Titles) != 3 || strings.Join(resp.Data.NewTags, ",") != "标签1,标签2" {
+		t.Errorf("推荐结果不正确: %+v", resp.Data)
+	}
+}
+
+func TestTranslateArticleSavesDraft(t *testing.T) {
+	setupAITest(t)
+	author := testutil.CreateUser(t, "author")
+	r := newAIRouter(author.ID)
+
+	original, err := services.CreateArticle(services.CreateArticleRequest{
+		Title:    "并发模式",
+		Content:  "使用通道传递数据。",
+		Status:   "published",
+		Language: "zh",
+	}, author.ID)
+	if err != nil {
+		t.Fatalf("创建原文失败: %v", err)
+	}
+
+	w := postJSON(r, "/ai/translate", fmt.Sprintf(`{"article_id": %d, "target_language": "en"}`, original.ID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 %d: %s", w.Code, w.Body.String())
+	}
+
+	events := parseSSE(w.Body.String())
+	if len(events) < 2 || events[len(events)-1].Data != "[DONE]" {
+		t.Fatalf("应以 [DONE] 结束: %s", w.Body.String())
+	}
+	saved := events[len(events)-2]
+	if saved.Name != "saved" {
+		t.Fatalf("[DONE] 之前应为 saved 事件: %+v", saved)
+	}
+	var result struct {
+		ArticleID uint   `json:"article_id"`
+		Language  string `json:"language"`
+	}
+	if err := json.Unmarshal([]byte(saved.Data), &result); err != nil || result.Language != "en" {
+		t.Fatalf("saved 事件数据不正确: %s", saved.Data)
+	}
+
+	var translation models.Article
+	if err := database.DB.First(&translation, result.ArticleID).Error; err != nil {
+		t.Fatalf("译文未保存: %v", err)
+	}
+	if translation.Status != "draft" || translation.AuthorID != author.ID ||
+		translation.TranslationOfID == nil || *translation.TranslationOfID != original.ID {
+		t.Errorf("译文应为关联到原文的草稿: %+v", translation)
+	}
+
+	w = postJSON(r, "/ai/translate", fmt.Sprintf(`{"article_id": %d, "target_language": "zh"}`, original.ID))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("目标语言与原文相同应返回 400，实际 %d", w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CollectPageView 上报页面访问（无 Cookie，不保存IP）
+func CollectPageView(c *gin.Context) {
+	var req services.PageViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	// 尊重浏览器的“请勿跟踪”设置
+	if c.GetHeader("DNT") == "1" {
+		utils.Success(c, nil)
+		return
+	}
+
+	services.RecordPageView(req, services.PageViewContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Host:      c.Request.Host,
+	})
+
+	utils.Success(c, nil)
+}
+
+// GetAnalyticsTraffic 获取每日流量
+func GetAnalyticsTraffic(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	points, err := services.GetTraffic(days)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, points)
+}
+
+// GetAnalyticsTopArticles 获取热门文章
+func GetAnalyticsTopArticles(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	articles, err := services.GetTopArticles(days, limit)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, articles)
+}
+
+// GetAnalyticsReferrers 获取主要来源
+func GetAnalyticsReferrers(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	referrers, err := services.GetTopReferrers(days, limit)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, referrers)
+}
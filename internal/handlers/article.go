@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"go-blog/internal/services"
 	"go-blog/pkg/utils"
 	"strconv"
@@ -25,6 +26,19 @@ func GetArticleList(c *gin.Context) {
 	utils.Success(c, resp)
 }
 
+// GetFeaturedArticles 获取推荐文章
+func GetFeaturedArticles(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	articles, err := services.GetFeaturedArticles(limit, c.Query("lang"))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, articles)
+}
+
 // GetArticleByID 获取文章详情
 func GetArticleByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -39,9 +53,34 @@ func GetArticleByID(c *gin.Context) {
 		return
 	}
 
+	// 统计浏览量，已登录作者的预览不计数
+	if _, isAuthor := c.Get("user_id"); !isAuthor && article.Status == "published" {
+		fingerprint := utils.Fingerprint(c.ClientIP(), c.Request.UserAgent())
+		if services.RecordArticleView(article.ID, fingerprint, c.Request.UserAgent()) {
+			article.ViewCount++
+		}
+	}
+
 	utils.Success(c, article)
 }
 
+// GetArticleTranslations 获取文章的所有其他语言版本（包括草稿）
+func GetArticleTranslations(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	article, err := services.GetArticleByID(uint(id))
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	utils.Success(c, services.GetArticleTranslations(&article.Article, false))
+}
+
 // CreateArticle 创建文章
 func CreateArticle(c *gin.Context) {
 	var req services.CreateArticleRequest
@@ -102,13 +141,53 @@ func DeleteArticle(c *gin.Context) {
 	utils.SuccessWithMessage(c, "文章删除成功", nil)
 }
 
-// SearchArticles 搜索文章
+// GetRelatedArticles 获取相关文章
+func GetRelatedArticles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的文章ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	articles, err := services.GetRelatedArticles(uint(id), limit)
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	utils.Success(c, articles)
+}
+
+// SearchArticles 搜索文章，mode 可选 keyword（默认）、semantic、hybrid
 func SearchArticles(c *gin.Context) {
 	keyword := c.Query("keyword")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	resp, err := services.SearchArticles(keyword, page, pageSize)
+	lang := c.Query("lang")
+
+	var resp *services.ArticleListResponse
+	var err error
+	switch mode := c.DefaultQuery("mode", services.SearchModeKeyword); mode {
+	case services.SearchModeKeyword:
+		resp, err = services.SearchArticles(keyword, lang, page, pageSize)
+	case services.SearchModeSemantic, services.SearchModeHybrid:
+		// 计算搜索词的向量计入AI用量
+		userID := c.GetUint("user_id")
+		if !checkAIQuota(c, userID) {
+			return
+		}
+		ctx := aiContext(c, userID)
+		resp, err = services.SemanticSearchArticles(ctx, keyword, lang, mode == services.SearchModeHybrid, page, pageSize)
+		if errors.Is(err, services.ErrEmbeddingDisabled) {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	default:
+		utils.BadRequest(c, "不支持的搜索模式: "+mode)
+		return
+	}
 	if err != nil {
 		utils.InternalServerError(c, err.Error())
 		return
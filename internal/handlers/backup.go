@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBackupFileSize 恢复文件大小上限
+const maxBackupFileSize = 500 << 20
+
+// ExportBackup 下载全站备份（zip），format=markdown 时文章以 Markdown 文件保存
+// 压缩包直接写入响应，不在内存中缓存
+func ExportBackup(c *gin.Context) {
+	filename := fmt.Sprintf("backup-%s.zip", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Type", "application/zip")
+
+	if _, err := services.ExportBackup(c.Writer, c.Query("format")); err != nil {
+		if c.Writer.Written() {
+			// 已开始输出，无法再返回错误信息
+			log.Printf("导出备份失败: %v", err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		utils.InternalServerError(c, err.Error())
+	}
+}
+
+// RestoreBackup 从备份文件恢复到空数据库
+func RestoreBackup(c *gin.Context) {
+	// 限制请求体大小，预留表单其他字段的空间
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBackupFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.BadRequest(c, "备份文件不能超过500MB")
+		return
+	}
+	if err != nil {
+		utils.BadRequest(c, "请上传备份文件")
+		return
+	}
+	if fileHeader.Size > maxBackupFileSize {
+		utils.BadRequest(c, "备份文件不能超过500MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalServerError(c, "读取备份文件失败")
+		return
+	}
+	defer file.Close()
+
+	manifest, err := services.RestoreBackup(file, fileHeader.Size)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "恢复完成，请使用备份中的账号重新登录", manifest)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"go-blog/internal/database"
 	"go-blog/internal/models"
+	"go-blog/internal/services"
 	"go-blog/pkg/utils"
 	"strconv"
 
@@ -89,5 +90,8 @@ func DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	// 标签和分类变化会影响相关文章得分
+	services.InvalidateRelatedCache()
+
 	utils.SuccessWithMessage(c, "分类删除成功", nil)
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"go-blog/internal/database"
 	"go-blog/internal/models"
+	"go-blog/internal/services"
 	"go-blog/pkg/utils"
 	"strconv"
 
@@ -24,6 +25,7 @@ func GetCommentsByArticleID(c *gin.Context) {
 		utils.InternalServerError(c, err.Error())
 		return
 	}
+	services.AttachCommentReactions(comments)
 
 	utils.Success(c, comments)
 }
@@ -36,15 +38,8 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
-	// 验证文章是否存在
-	var article models.Article
-	if err := database.DB.First(&article, comment.ArticleID).Error; err != nil {
-		utils.Error(c, 404, "文章不存在")
-		return
-	}
-
-	if err := database.DB.Create(&comment).Error; err != nil {
-		utils.InternalServerError(c, "发表评论失败")
+	if err := services.CreateComment(&comment); err != nil {
+		utils.Error(c, 400, err.Error())
 		return
 	}
 
@@ -59,14 +54,8 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	var comment models.Comment
-	if err := database.DB.First(&comment, id).Error; err != nil {
-		utils.Error(c, 404, "评论不存在")
-		return
-	}
-
-	if err := database.DB.Delete(&comment).Error; err != nil {
-		utils.InternalServerError(c, "删除评论失败")
+	if err := services.DeleteComment(uint(id)); err != nil {
+		utils.Error(c, 400, err.Error())
 		return
 	}
 
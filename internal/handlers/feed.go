@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go-blog/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRSSFeed 输出最近发布文章的 RSS 订阅，可通过 lang 参数指定语言
+func GetRSSFeed(c *gin.Context) {
+	data, err := services.BuildRSS(c.Query("lang"))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "生成订阅失败")
+		return
+	}
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", data)
+}
+
+// GetSitemap 输出站点地图
+func GetSitemap(c *gin.Context) {
+	data, err := services.BuildSitemap()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "生成站点地图失败")
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 50 << 20
+
+// ImportContent 导入 WordPress WXR 文件或 Markdown 文件（单个文件或 zip 压缩包）
+// dry_run=true 时仅返回导入预览，不写入数据
+func ImportContent(c *gin.Context) {
+	// 限制请求体大小，预留表单其他字段的空间
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.BadRequest(c, "导入文件不能超过50MB")
+		return
+	}
+	if err != nil {
+		utils.BadRequest(c, "请上传导入文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.BadRequest(c, "导入文件不能超过50MB")
+		return
+	}
+
+	format := c.PostForm("format")
+	dryRun := c.PostForm("dry_run") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.InternalServerError(c, "读取导入文件失败")
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		utils.InternalServerError(c, "读取导入文件失败")
+		return
+	}
+
+	data, err := services.ParseImportFile(format, fileHeader.Filename, content)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	report, err := services.Import(data, services.ImportOptions{
+		AuthorID: userID.(uint),
+		DryRun:   dryRun,
+	})
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	if dryRun {
+		utils.SuccessWithMessage(c, "导入预览", report)
+		return
+	}
+	utils.SuccessWithMessage(c, "导入完成", report)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Subscribe 订阅新文章邮件
+func Subscribe(c *gin.Context) {
+	var req services.SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if err := services.Subscribe(req.Email); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "确认邮件已发送，请查收邮箱完成订阅", nil)
+}
+
+// ConfirmSubscription 确认订阅
+func ConfirmSubscription(c *gin.Context) {
+	if err := services.ConfirmSubscription(c.Query("token")); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "订阅成功", nil)
+}
+
+// Unsubscribe 退订
+func Unsubscribe(c *gin.Context) {
+	if err := services.Unsubscribe(c.Query("token")); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已退订", nil)
+}
+
+// GetSubscribers 获取订阅者列表
+func GetSubscribers(c *gin.Context) {
+	var query services.SubscriberListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resp, err := services.GetSubscribers(query)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// ExportSubscribers 导出订阅者（CSV）
+func ExportSubscribers(c *gin.Context) {
+	subscribers, err := services.GetAllSubscribers(c.Query("status"))
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("subscribers-%s.csv", time.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"email", "status", "confirmed_at", "created_at"})
+	for _, s := range subscribers {
+		confirmedAt := ""
+		if s.ConfirmedAt != nil {
+			confirmedAt = s.ConfirmedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{s.Email, s.Status, confirmedAt, s.CreatedAt.Format(time.RFC3339)})
+	}
+	w.Flush()
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromptTemplateRequest 保存提示词模板请求
+type PromptTemplateRequest struct {
+	Content string `json:"content" binding:"required"`
+	Note    string `json:"note"`
+}
+
+// PromptPreviewRequest 预览提示词请求
+type PromptPreviewRequest struct {
+	Content string          `json:"content"` // 为空时预览当前使用的模板
+	Data    json.RawMessage `json:"data"`    // 示例请求，字段与对应的AI接口一致
+}
+
+// GetPromptTemplates 获取所有提示词模板
+func GetPromptTemplates(c *gin.Context) {
+	templates, err := services.GetPromptTemplates()
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, templates)
+}
+
+// GetPromptTemplate 获取提示词模板的全部版本
+func GetPromptTemplate(c *gin.Context) {
+	detail, err := services.GetPromptTemplate(c.Param("name"))
+	if err != nil {
+		promptTemplateError(c, err)
+		return
+	}
+
+	utils.Success(c, detail)
+}
+
+// SavePromptTemplate 保存提示词模板为新版本并启用
+func SavePromptTemplate(c *gin.Context) {
+	var req PromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	tmpl, err := services.SavePromptTemplate(c.Param("name"), req.Content, req.Note, c.GetUint("user_id"))
+	if err != nil {
+		promptTemplateError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "提示词模板保存成功", tmpl)
+}
+
+// ActivatePromptTemplate 启用提示词模板的指定版本
+func ActivatePromptTemplate(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		utils.BadRequest(c, "无效的版本号")
+		return
+	}
+
+	tmpl, err := services.ActivatePromptTemplate(c.Param("name"), version)
+	if err != nil {
+		promptTemplateError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已切换到该版本", tmpl)
+}
+
+// ResetPromptTemplate 恢复使用内置提示词模板
+func ResetPromptTemplate(c *gin.Context) {
+	if err := services.ResetPromptTemplate(c.Param("name")); err != nil {
+		promptTemplateError(c, err)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已恢复内置模板", nil)
+}
+
+// PreviewPromptTemplate 使用示例数据预览提示词
+func PreviewPromptTemplate(c *gin.Context) {
+	var req PromptPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	prompt, err := services.PreviewPrompt(c.Param("name"), req.Content, req.Data)
+	if err != nil {
+		promptTemplateError(c, err)
+		return
+	}
+
+	utils.Success(c, gin.H{"prompt": prompt})
+}
+
+// promptTemplateError 模板不存在返回 404，其余为参数或模板错误
+func promptTemplateError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrPromptTemplateNotFound) {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+	utils.Error(c, 400, err.Error())
+}
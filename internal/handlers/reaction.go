@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToggleArticleReaction 对文章表态（再次提交同一表态则取消）
+func ToggleArticleReaction(c *gin.Context) {
+	toggleReaction(c, services.ReactionTargetArticle, "无效的文章ID")
+}
+
+// ToggleCommentReaction 对评论表态（再次提交同一表态则取消）
+func ToggleCommentReaction(c *gin.Context) {
+	toggleReaction(c, services.ReactionTargetComment, "无效的评论ID")
+}
+
+// toggleReaction 处理表态请求，访客以匿名指纹去重
+func toggleReaction(c *gin.Context, targetType, invalidIDMessage string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, invalidIDMessage)
+		return
+	}
+
+	var req services.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	fingerprint := services.ReactionFingerprint(c.ClientIP(), c.Request.UserAgent())
+	result, err := services.ToggleReaction(targetType, uint(id), req.Kind, fingerprint)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSeriesList 获取系列列表
+func GetSeriesList(c *gin.Context) {
+	list, err := services.GetSeriesList()
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, list)
+}
+
+// GetSeriesByID 获取系列详情
+func GetSeriesByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的系列ID")
+		return
+	}
+
+	// 只有作者可以查看系列中的草稿
+	role, _ := c.Get("role")
+	showAll := c.Query("show_all") == "true" && role == "author"
+	series, err := services.GetSeriesByID(uint(id), showAll)
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	utils.Success(c, series)
+}
+
+// CreateSeries 创建系列
+func CreateSeries(c *gin.Context) {
+	var req services.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	series, err := services.CreateSeries(req)
+	if err != nil {
+		utils.InternalServerError(c, "创建系列失败")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "系列创建成功", series)
+}
+
+// UpdateSeries 更新系列
+func UpdateSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的系列ID")
+		return
+	}
+
+	var req services.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	series, err := services.UpdateSeries(uint(id), req)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "系列更新成功", series)
+}
+
+// DeleteSeries 删除系列
+func DeleteSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的系列ID")
+		return
+	}
+
+	if err := services.DeleteSeries(uint(id)); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "系列删除成功", nil)
+}
+
+// SetSeriesArticles 设置系列文章及顺序
+func SetSeriesArticles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的系列ID")
+		return
+	}
+
+	var req services.SeriesArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	series, err := services.SetSeriesArticles(uint(id), req)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "系列文章更新成功", series)
+}
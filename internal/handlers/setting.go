@@ -9,13 +9,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// GetSettings 获取所有设置
+// GetSettings 获取所有设置，指定 lang 时站点名称、描述等使用该语言的值
 func GetSettings(c *gin.Context) {
 	settings, err := services.GetSettings()
 	if err != nil {
 		utils.Error(c, http.StatusInternalServerError, "获取设置失败")
 		return
 	}
+	if lang := c.Query("lang"); lang != "" {
+		language, err := services.NormalizeLanguage(lang)
+		if err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+		settings = services.LocalizeSettings(settings, language)
+	}
 
 	utils.Success(c, settings)
 }
@@ -35,3 +43,14 @@ func UpdateSettings(c *gin.Context) {
 
 	utils.Success(c, gin.H{"message": "设置更新成功"})
 }
+
+// GetLanguages 获取站点使用的语言
+func GetLanguages(c *gin.Context) {
+	languages, err := services.GetLanguages()
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "获取语言失败")
+		return
+	}
+
+	utils.Success(c, languages)
+}
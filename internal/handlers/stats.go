@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDashboardStats 获取后台概览统计
+func GetDashboardStats(c *gin.Context) {
+	stats, err := services.GetDashboardStats()
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, stats)
+}
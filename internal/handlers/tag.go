@@ -3,6 +3,7 @@ package handlers
 import (
 	"go-blog/internal/database"
 	"go-blog/internal/models"
+	"go-blog/internal/services"
 	"go-blog/pkg/utils"
 	"strconv"
 
@@ -55,5 +56,8 @@ func DeleteTag(c *gin.Context) {
 		return
 	}
 
+	// 标签和分类变化会影响相关文章得分
+	services.InvalidateRelatedCache()
+
 	utils.SuccessWithMessage(c, "标签删除成功", nil)
 }
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"go-blog/internal/services"
+	"go-blog/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhooks 获取 Webhook 列表
+func GetWebhooks(c *gin.Context) {
+	webhooks, err := services.GetWebhooks()
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, webhooks)
+}
+
+// CreateWebhook 创建 Webhook
+func CreateWebhook(c *gin.Context) {
+	var req services.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	webhook, err := services.CreateWebhook(req)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Webhook创建成功", webhook)
+}
+
+// UpdateWebhook 更新 Webhook
+func UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的Webhook ID")
+		return
+	}
+
+	var req services.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	webhook, err := services.UpdateWebhook(uint(id), req)
+	if err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Webhook更新成功", webhook)
+}
+
+// DeleteWebhook 删除 Webhook
+func DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的Webhook ID")
+		return
+	}
+
+	if err := services.DeleteWebhook(uint(id)); err != nil {
+		utils.Error(c, 400, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Webhook删除成功", nil)
+}
+
+// GetWebhookDeliveries 获取 Webhook 投递记录
+func GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的Webhook ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	resp, err := services.GetWebhookDeliveries(uint(id), page, pageSize)
+	if err != nil {
+		utils.InternalServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// RedeliverWebhook 重新投递
+func RedeliverWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效的投递记录ID")
+		return
+	}
+
+	delivery, err := services.RedeliverWebhook(uint(id))
+	if err != nil {
+		utils.Error(c, 404, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已重新投递", delivery)
+}
@@ -54,3 +54,18 @@ func AuthorOnly() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuth 可选认证，携带有效令牌时写入用户信息，否则按访客处理
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := utils.ParseToken(parts[1]); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("role", claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
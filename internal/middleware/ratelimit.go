@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"go-blog/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitWindow 单个客户端的计数窗口
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit 按客户端IP限流的中间件，每个时间窗口内最多允许 limit 次请求
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	clients := make(map[string]*rateLimitWindow)
+	lastCleanup := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// 定期清理过期记录，避免内存持续增长
+		if now.Sub(lastCleanup) > window {
+			for key, w := range clients {
+				if now.Sub(w.start) >= window {
+					delete(clients, key)
+				}
+			}
+			lastCleanup = now
+		}
+
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.start) >= window {
+			w = &rateLimitWindow{start: now}
+			clients[ip] = w
+		}
+		w.count++
+		exceeded := w.count > limit
+		mu.Unlock()
+
+		if exceeded {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, utils.Response{
+				Code:    http.StatusTooManyRequests,
+				Message: "请求过于频繁，请稍后再试",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AIUsage AI调用的 token 用量（按日期、用户和操作聚合）
+type AIUsage struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Date             string    `gorm:"size:10;not null;uniqueIndex:idx_ai_usage" json:"date"`      // 2006-01-02
+	UserID           uint      `gorm:"not null;default:0;uniqueIndex:idx_ai_usage" json:"user_id"` // 0 表示系统调用（如自动生成摘要）或未登录读者
+	Operation        string    `gorm:"size:20;not null;uniqueIndex:idx_ai_usage" json:"operation"` // generate, continue, polish, expand, summarize, suggest, translate, answer, embed；未登录读者的操作带 anonymous_ 前缀
+	Requests         int64     `gorm:"default:0" json:"requests"`
+	PromptTokens     int64     `gorm:"default:0" json:"prompt_tokens"`
+	CompletionTokens int64     `gorm:"default:0" json:"completion_tokens"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AIUsage) TableName() string {
+	return "ai_usages"
+}
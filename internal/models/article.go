@@ -1,23 +1,83 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
 // Article 文章模型
 type Article struct {
-	ID         uint       `gorm:"primaryKey" json:"id"`
-	Title      string     `gorm:"size:255;not null" json:"title"`
-	Content    string     `gorm:"type:text;not null" json:"content"`
-	Summary    string     `gorm:"size:500" json:"summary"`
-	AuthorID   uint       `gorm:"not null;index" json:"author_id"`
-	Author     User       `gorm:"foreignKey:AuthorID" json:"author"`
-	Categories []Category `gorm:"many2many:article_categories;" json:"categories"` // 改为多对多
-	Tags       []Tag      `gorm:"many2many:article_tags;" json:"tags"`
-	Status     string     `gorm:"size:20;default:draft" json:"status"` // draft, published
-	ViewCount  int        `gorm:"default:0" json:"view_count"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID               uint             `gorm:"primaryKey" json:"id"`
+	Title            string           `gorm:"size:255;not null" json:"title"`
+	Content          string           `gorm:"type:text;not null" json:"content"`
+	Summary          string           `gorm:"size:500" json:"summary"`
+	SummaryAuto      bool             `gorm:"default:false" json:"summary_auto"` // 摘要是否为自动生成
+	AuthorID         uint             `gorm:"not null;index" json:"author_id"`
+	Author           User             `gorm:"foreignKey:AuthorID" json:"author"`
+	Categories       []Category       `gorm:"many2many:article_categories;" json:"categories"` // 改为多对多
+	Tags             []Tag            `gorm:"many2many:article_tags;" json:"tags"`
+	Status           string           `gorm:"size:20;default:draft" json:"status"`               // draft, published
+	Language         string           `gorm:"size:10;not null;default:zh;index" json:"language"` // 语言代码，如 zh、en
+	TranslationOfID  *uint            `gorm:"index" json:"translation_of_id"`                    // 译文对应的原文ID，原文为空
+	SeriesID         *uint            `gorm:"index" json:"series_id"`                            // 所属系列
+	SeriesOrder      int              `gorm:"default:0" json:"series_order"`                     // 系列内顺序
+	ViewCount        int              `gorm:"default:0" json:"view_count"`
+	IsPinned         bool             `gorm:"default:false;index" json:"is_pinned"`   // 置顶
+	IsFeatured       bool             `gorm:"default:false;index" json:"is_featured"` // 推荐（首页轮播）
+	Weight           int              `gorm:"default:0" json:"weight"`                // 排序权重，越大越靠前
+	TOC              ArticleTOC       `gorm:"type:text" json:"toc"`                   // 目录（由内容标题生成）
+	WordCount        int              `gorm:"default:0" json:"word_count"`            // 字数（中日韩字符按字计，其他按词计）
+	ReadingTime      int              `gorm:"default:0" json:"reading_time"`          // 预计阅读时长（分钟）
+	Reactions        map[string]int64 `gorm:"-" json:"reactions"`                     // 各类表态数量
+	PublishedAt      *time.Time       `gorm:"index" json:"published_at"`              // 首次发布时间
+	NewsletterSentAt *time.Time       `json:"-"`                                      // 新文章邮件推送时间，避免重复推送
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// TOCItem 文章目录项
+type TOCItem struct {
+	Level  int    `json:"level"`  // 标题层级 1-6
+	Text   string `json:"text"`   // 标题文本
+	Anchor string `json:"anchor"` // 锚点
+}
+
+// ArticleTOC 文章目录，以JSON形式存储
+type ArticleTOC []TOCItem
+
+// Value 实现 driver.Valuer 接口
+func (t ArticleTOC) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *ArticleTOC) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = ArticleTOC{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("无法解析文章目录数据")
+	}
+	if len(data) == 0 {
+		*t = ArticleTOC{}
+		return nil
+	}
+	return json.Unmarshal(data, t)
 }
 
 // TableName 指定表名
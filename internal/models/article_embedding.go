@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// ArticleEmbedding 文章的向量表示，用于语义搜索
+type ArticleEmbedding struct {
+	ArticleID   uint      `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	Model       string    `gorm:"size:100;not null" json:"model"`       // 计算向量的模型，切换模型后需重新计算
+	ContentHash string    `gorm:"size:64;not null" json:"content_hash"` // 计算时文本的 SHA-256，未变化时跳过
+	Dimensions  int       `gorm:"not null" json:"dimensions"`
+	Vector      Vector    `gorm:"type:blob;not null" json:"-"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ArticleEmbedding) TableName() string {
+	return "article_embeddings"
+}
+
+// Vector 向量，以小端序 float32 的二进制形式存储
+type Vector []float32
+
+// Value 实现 driver.Valuer 接口
+func (v Vector) Value() (driver.Value, error) {
+	data := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(f))
+	}
+	return data, nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (v *Vector) Scan(value interface{}) error {
+	var data []byte
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return errors.New("无法解析向量数据")
+	}
+	if len(data)%4 != 0 {
+		return errors.New("向量数据长度错误")
+	}
+	vector := make(Vector, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	*v = vector
+	return nil
+}
+
+// ArticleChunk 文章的分段及其向量，用于问答时检索相关内容
+type ArticleChunk struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ArticleID   uint      `gorm:"not null;index" json:"article_id"`
+	Position    int       `gorm:"not null" json:"position"` // 在文章中的顺序
+	Heading     string    `gorm:"size:255" json:"heading"`  // 所在章节的标题，位于第一个标题之前时为空
+	Anchor      string    `gorm:"size:255" json:"anchor"`   // 所在章节的锚点
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	Model       string    `gorm:"size:100;not null" json:"model"`
+	ContentHash string    `gorm:"size:64;not null" json:"content_hash"` // 分段时文章标题和正文的 SHA-256，未变化时不重新分段
+	Vector      Vector    `gorm:"type:blob;not null" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ArticleChunk) TableName() string {
+	return "article_chunks"
+}
@@ -6,12 +6,14 @@ import (
 
 // Comment 评论模型
 type Comment struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	ArticleID uint      `gorm:"not null;index" json:"article_id"`
-	Nickname  string    `gorm:"size:50;not null" json:"nickname"`
-	Email     string    `gorm:"size:100" json:"email"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	ArticleID uint             `gorm:"not null;index" json:"article_id"`
+	ParentID  *uint            `gorm:"index" json:"parent_id"` // 回复的评论ID
+	Nickname  string           `gorm:"size:50;not null" json:"nickname"`
+	Email     string           `gorm:"size:100" json:"email"`
+	Content   string           `gorm:"type:text;not null" json:"content"`
+	Reactions map[string]int64 `gorm:"-" json:"reactions"` // 各类表态数量
+	CreatedAt time.Time        `json:"created_at"`
 }
 
 // TableName 指定表名
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// MailQueue 待发送邮件队列
+type MailQueue struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	To            string     `gorm:"size:255;not null" json:"to"`
+	Subject       string     `gorm:"size:255;not null" json:"subject"`
+	Body          string     `gorm:"type:text;not null" json:"body"`
+	Status        string     `gorm:"size:20;default:pending;index" json:"status"` // pending, sent, failed
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	LastError     string     `gorm:"size:1000" json:"last_error"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MailQueue) TableName() string {
+	return "mail_queue"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// NewsletterDigest 每周摘要发送记录，用于判断本周是否已发送
+type NewsletterDigest struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Articles   int       `gorm:"default:0" json:"articles"`   // 摘要中的文章数，没有新文章时为0且不发送邮件
+	Recipients int       `gorm:"default:0" json:"recipients"` // 成功入队的收件人数
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (NewsletterDigest) TableName() string {
+	return "newsletter_digests"
+}
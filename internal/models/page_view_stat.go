@@ -0,0 +1,21 @@
+package models
+
+// PageViewStat 页面访问日统计（按日期、路径、来源、国家和设备聚合，不保存访客信息）
+type PageViewStat struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	Date          string `gorm:"size:10;not null;uniqueIndex:idx_page_view_stat" json:"date"` // 2006-01-02
+	Path          string `gorm:"size:255;not null;uniqueIndex:idx_page_view_stat" json:"path"`
+	ReferrerHost  string `gorm:"size:255;not null;default:'';uniqueIndex:idx_page_view_stat" json:"referrer_host"`
+	Country       string `gorm:"size:8;not null;default:'';uniqueIndex:idx_page_view_stat" json:"country"`
+	Device        string `gorm:"size:20;not null;default:'';uniqueIndex:idx_page_view_stat" json:"device"` // desktop, mobile, tablet
+	ArticleID     uint   `gorm:"default:0;index" json:"article_id"`                                        // 文章详情页对应的文章ID
+	Views         int    `gorm:"default:0" json:"views"`
+	Visitors      int    `gorm:"default:0" json:"visitors"`       // 当日访问该路径、来源的访客数
+	PathVisitors  int    `gorm:"default:0" json:"path_visitors"`  // 当日首次访问该路径落在此行的访客数，按日期和路径求和即该页面当日访客数
+	DailyVisitors int    `gorm:"default:0" json:"daily_visitors"` // 当日首次访问落在此行的访客数，按日期求和即当日访客数
+}
+
+// TableName 指定表名
+func (PageViewStat) TableName() string {
+	return "page_view_stats"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PromptTemplate AI提示词模板（Go text/template），每次修改保存为新版本
+type PromptTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:20;not null;uniqueIndex:idx_prompt_template_version" json:"name"` // generate, continue, polish, expand, summarize, suggest, translate, answer
+	Version   int       `gorm:"not null;uniqueIndex:idx_prompt_template_version" json:"version"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	Note      string    `gorm:"size:255" json:"note"`         // 修改说明
+	Active    bool      `gorm:"not null;index" json:"active"` // 当前使用的版本，同名模板最多一个；都未启用时使用内置模板
+	CreatedBy uint      `gorm:"not null;default:0" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Reaction 读者匿名表态（点赞、表情）
+type Reaction struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TargetType  string    `gorm:"size:20;not null;uniqueIndex:idx_reaction_visitor;index:idx_reaction_target" json:"target_type"` // article, comment
+	TargetID    uint      `gorm:"not null;uniqueIndex:idx_reaction_visitor;index:idx_reaction_target" json:"target_id"`
+	Kind        string    `gorm:"size:20;not null;uniqueIndex:idx_reaction_visitor" json:"kind"`
+	Fingerprint string    `gorm:"size:64;not null;uniqueIndex:idx_reaction_visitor" json:"-"` // 访客匿名指纹
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (Reaction) TableName() string {
+	return "reactions"
+}
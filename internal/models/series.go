@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Series 系列文章模型
+type Series struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Title       string    `gorm:"size:255;not null" json:"title"`
+	Description string    `gorm:"size:500" json:"description"`
+	Articles    []Article `gorm:"foreignKey:SeriesID" json:"articles,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Series) TableName() string {
+	return "series"
+}
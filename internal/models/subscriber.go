@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Subscriber 邮件订阅者
+type Subscriber struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	Email            string     `gorm:"size:100;uniqueIndex;not null" json:"email"`
+	Status           string     `gorm:"size:20;default:pending;index" json:"status"` // pending, active, unsubscribed
+	ConfirmToken     string     `gorm:"size:64;index" json:"-"`
+	UnsubscribeToken string     `gorm:"size:64;uniqueIndex" json:"-"`
+	ConfirmedAt      *time.Time `json:"confirmed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Subscriber) TableName() string {
+	return "subscribers"
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Webhook 外发 Webhook 配置
+type Webhook struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"size:100;not null" json:"name"`
+	URL       string     `gorm:"size:500;not null" json:"url"`
+	Secret    string     `gorm:"size:255" json:"secret"`  // HMAC-SHA256 签名密钥
+	Events    StringList `gorm:"type:text" json:"events"` // 订阅的事件
+	Active    bool       `gorm:"not null" json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery Webhook 投递记录
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	Event          string     `gorm:"size:50;not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:20;default:pending;index" json:"status"` // pending, success, failed
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	ResponseStatus int        `gorm:"default:0" json:"response_status"`
+	ResponseBody   string     `gorm:"size:2000" json:"response_body"`
+	LastError      string     `gorm:"size:1000" json:"last_error"`
+	NextAttemptAt  time.Time  `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// StringList 字符串列表，以JSON形式存储
+type StringList []string
+
+// Value 实现 driver.Valuer 接口
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("无法解析字符串列表数据")
+	}
+	if len(data) == 0 {
+		*l = StringList{}
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
+// TableName 指定表名
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
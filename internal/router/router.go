@@ -1,8 +1,12 @@
 package router
 
 import (
+	"time"
+
+	"go-blog/internal/config"
 	"go-blog/internal/handlers"
 	"go-blog/internal/middleware"
+	"go-blog/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +20,9 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 	r.Use(middleware.CORS())
 
 	// API路由组
+	// 问答和语义搜索调用付费的模型接口，共用一个较严格的限流
+	aiLimit := middleware.RateLimit(rateLimitRequests(), rateLimitWindow())
+
 	api := r.Group("/api")
 	{
 		// 公开接口
@@ -23,8 +30,10 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 
 		// 文章相关（公开）
 		api.GET("/articles", handlers.GetArticleList)
-		api.GET("/articles/:id", handlers.GetArticleByID)
-		api.GET("/articles/search", handlers.SearchArticles)
+		api.GET("/articles/featured", handlers.GetFeaturedArticles)
+		api.GET("/articles/:id", middleware.OptionalAuth(), handlers.GetArticleByID)
+		api.GET("/articles/:id/related", handlers.GetRelatedArticles)
+		api.GET("/articles/search", semanticSearchOnly(aiLimit), handlers.SearchArticles)
 
 		// 分类相关（公开）
 		api.GET("/categories", handlers.GetCategories)
@@ -32,12 +41,32 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 		// 标签相关（公开）
 		api.GET("/tags", handlers.GetTags)
 
+		// 系列相关（公开）
+		api.GET("/series", handlers.GetSeriesList)
+		api.GET("/series/:id", middleware.OptionalAuth(), handlers.GetSeriesByID)
+
 		// 评论相关（公开）
 		api.GET("/comments/:articleId", handlers.GetCommentsByArticleID)
-		api.POST("/comments", handlers.CreateComment)
+		// 评论会向作者和被回复者发送邮件，单独限流
+		api.POST("/comments", middleware.RateLimit(rateLimitRequests(), rateLimitWindow()), handlers.CreateComment)
 
 		// 设置相关（公开获取）
 		api.GET("/settings", handlers.GetSettings)
+		api.GET("/languages", handlers.GetLanguages)
+
+		// 公开写接口，按用途分别限流，互不占用额度
+		reactionLimit := middleware.RateLimit(rateLimitRequests(), rateLimitWindow())
+		api.POST("/articles/:id/reactions", reactionLimit, handlers.ToggleArticleReaction)
+		api.POST("/comments/:id/reactions", reactionLimit, handlers.ToggleCommentReaction)
+		api.POST("/newsletter/subscribe", middleware.RateLimit(rateLimitRequests(), rateLimitWindow()), handlers.Subscribe)
+		api.POST("/ask", aiLimit, handlers.AskBlog)
+
+		// 邮件订阅确认/退订（公开）
+		api.GET("/newsletter/confirm", handlers.ConfirmSubscription)
+		api.GET("/newsletter/unsubscribe", handlers.Unsubscribe)
+
+		// 访问统计上报（公开，限流）
+		api.POST("/analytics/collect", middleware.RateLimit(rateLimitRequests(), rateLimitWindow()), handlers.CollectPageView)
 
 		// 需要认证的接口
 		auth := api.Group("")
@@ -48,6 +77,7 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 			auth.POST("/articles", handlers.CreateArticle)
 			auth.PUT("/articles/:id", handlers.UpdateArticle)
 			auth.DELETE("/articles/:id", handlers.DeleteArticle)
+			auth.GET("/articles/:id/translations", handlers.GetArticleTranslations)
 
 			// 分类管理
 			auth.POST("/categories", handlers.CreateCategory)
@@ -58,6 +88,12 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 			auth.POST("/tags", handlers.CreateTag)
 			auth.DELETE("/tags/:id", handlers.DeleteTag)
 
+			// 系列管理
+			auth.POST("/series", handlers.CreateSeries)
+			auth.PUT("/series/:id", handlers.UpdateSeries)
+			auth.DELETE("/series/:id", handlers.DeleteSeries)
+			auth.PUT("/series/:id/articles", handlers.SetSeriesArticles)
+
 			// 评论管理
 			auth.DELETE("/comments/:id", handlers.DeleteComment)
 
@@ -67,14 +103,56 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 			// 用户管理
 			auth.POST("/user/password", handlers.ChangePassword)
 
+			// 后台概览
+			auth.GET("/admin/stats", handlers.GetDashboardStats)
+
+			// 订阅者管理
+			auth.GET("/admin/subscribers", handlers.GetSubscribers)
+			auth.GET("/admin/subscribers/export", handlers.ExportSubscribers)
+
+			// 内容导入
+			auth.POST("/admin/import", handlers.ImportContent)
+
+			// 备份与恢复
+			auth.GET("/admin/backup", handlers.ExportBackup)
+			auth.POST("/admin/restore", handlers.RestoreBackup)
+
+			// Webhook管理
+			auth.GET("/admin/webhooks", handlers.GetWebhooks)
+			auth.POST("/admin/webhooks", handlers.CreateWebhook)
+			auth.PUT("/admin/webhooks/:id", handlers.UpdateWebhook)
+			auth.DELETE("/admin/webhooks/:id", handlers.DeleteWebhook)
+			auth.GET("/admin/webhooks/:id/deliveries", handlers.GetWebhookDeliveries)
+			auth.POST("/admin/webhooks/deliveries/:id/redeliver", handlers.RedeliverWebhook)
+
+			// 访问统计
+			auth.GET("/admin/analytics/traffic", handlers.GetAnalyticsTraffic)
+			auth.GET("/admin/analytics/top-articles", handlers.GetAnalyticsTopArticles)
+			auth.GET("/admin/analytics/referrers", handlers.GetAnalyticsReferrers)
+
 			// AI写作辅助
 			auth.POST("/ai/generate", handlers.GenerateArticle)
 			auth.POST("/ai/continue", handlers.ContinueWriting)
 			auth.POST("/ai/polish", handlers.PolishArticle)
 			auth.POST("/ai/expand", handlers.ExpandOutline)
+			auth.POST("/ai/suggest-metadata", handlers.SuggestMetadata)
+			auth.POST("/ai/translate", handlers.TranslateArticle)
+			auth.GET("/admin/ai/usage", handlers.GetAIUsage)
+
+			// AI提示词模板
+			auth.GET("/admin/ai/prompts", handlers.GetPromptTemplates)
+			auth.GET("/admin/ai/prompts/:name", handlers.GetPromptTemplate)
+			auth.PUT("/admin/ai/prompts/:name", handlers.SavePromptTemplate)
+			auth.DELETE("/admin/ai/prompts/:name", handlers.ResetPromptTemplate)
+			auth.POST("/admin/ai/prompts/:name/preview", handlers.PreviewPromptTemplate)
+			auth.POST("/admin/ai/prompts/:name/versions/:version/activate", handlers.ActivatePromptTemplate)
 		}
 	}
 
+	// RSS 订阅和站点地图
+	r.GET("/feed.xml", handlers.GetRSSFeed)
+	r.GET("/sitemap.xml", handlers.GetSitemap)
+
 	// 静态文件和SPA路由（如果提供了handler）
 	if len(spaHandler) > 0 && spaHandler[0] != nil {
 		r.NoRoute(spaHandler[0])
@@ -82,3 +160,30 @@ func SetupRouter(spaHandler ...gin.HandlerFunc) *gin.Engine {
 
 	return r
 }
+
+// semanticSearchOnly 仅对语义搜索和混合搜索应用中间件，关键词搜索不受影响
+func semanticSearchOnly(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if mode := c.Query("mode"); mode == "" || mode == services.SearchModeKeyword {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
+
+// rateLimitRequests 限流时间窗口内允许的请求数
+func rateLimitRequests() int {
+	if n := config.AppConfig.RateLimit.Requests; n > 0 {
+		return n
+	}
+	return 30
+}
+
+// rateLimitWindow 限流时间窗口
+func rateLimitWindow() time.Duration {
+	if n := config.AppConfig.RateLimit.Window; n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return time.Minute
+}
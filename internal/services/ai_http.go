@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultAIIdleTimeout 上游接口无数据返回的最长等待时间
+const defaultAIIdleTimeout = 60 * time.Second
+
+// ErrAIIdleTimeout 上游接口在空闲超时时间内没有返回任何数据
+var ErrAIIdleTimeout = errors.New("AI接口响应超时")
+
+// newAIHTTPClient 创建调用模型接口的 HTTP 客户端
+// 不设置总超时，长文生成可以持续输出；连接和等待响应头分别限时，读取正文的空闲超时由 doAIRequest 控制
+func newAIHTTPClient(idleTimeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.ResponseHeaderTimeout = idleTimeout
+	return &http.Client{Transport: transport}
+}
+
+// doAIRequest 发送请求，ctx 取消（如浏览器断开连接）时中止上游请求
+// 返回的响应正文每次读到数据都会重置空闲计时，超过 idleTimeout 没有数据时中止请求并返回 ErrAIIdleTimeout
+func doAIRequest(ctx context.Context, client *http.Client, req *http.Request, idleTimeout time.Duration) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(idleTimeout, func() { cancel(ErrAIIdleTimeout) })
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		timer.Stop()
+		cancel(nil)
+		return nil, idleTimeoutError(ctx, err)
+	}
+
+	resp.Body = &idleTimeoutBody{
+		ReadCloser:  resp.Body,
+		ctx:         ctx,
+		cancel:      cancel,
+		timer:       timer,
+		idleTimeout: idleTimeout,
+	}
+	return resp, nil
+}
+
+// idleTimeoutBody 读到数据时重置空闲计时的响应正文
+type idleTimeoutBody struct {
+	io.ReadCloser
+	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	timer       *time.Timer
+	idleTimeout time.Duration
+}
+
+// Read 读取正文并重置空闲计时
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.idleTimeout)
+	}
+	if err != nil && err != io.EOF {
+		err = idleTimeoutError(b.ctx, err)
+	}
+	return n, err
+}
+
+// Close 关闭正文并释放计时器
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel(nil)
+	return b.ReadCloser.Close()
+}
+
+// idleTimeoutError 因空闲超时中止的请求返回 ErrAIIdleTimeout，其余错误原样返回
+func idleTimeoutError(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrAIIdleTimeout) {
+		return ErrAIIdleTimeout
+	}
+	return err
+}
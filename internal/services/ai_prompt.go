@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// promptFuncs 提示词模板可用的函数
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// promptDefinition 提示词模板的定义
+type promptDefinition struct {
+	// defaultContent 内置模板，数据库中没有启用的模板时使用
+	defaultContent string
+	// newRequest 创建该操作的请求，用于预览时解析示例数据
+	newRequest func() interface{}
+	// data 补齐请求的默认值，作为模板数据
+	data func(req interface{}) interface{}
+
+	defaultTemplate *template.Template
+}
+
+// promptDefinitions 各操作的提示词模板
+var promptDefinitions = map[string]*promptDefinition{
+	AIOperationGenerate: {
+		defaultContent: `你是一位专业的技术博客作者。请根据以下要求生成一篇文章：
+
+标题：{{.Title}}
+{{if .Keywords}}关键词：{{join .Keywords "、"}}
+{{end}}{{if .Outline}}大纲：
+{{.Outline}}
+{{end}}字数要求：约{{.WordCount}}字
+
+要求：
+1. 使用Markdown格式
+2. 包含清晰的段落结构，使用##、###等标题层级
+3. 适当使用代码示例（使用` + "```" + `代码块）
+4. 语言专业且易懂
+5. 内容充实，有深度
+6. 直接输出文章内容，不要额外的解释`,
+		newRequest: func() interface{} { return &GenerateArticleRequest{} },
+		data: func(req interface{}) interface{} {
+			data := *req.(*GenerateArticleRequest)
+			if data.WordCount == 0 {
+				data.WordCount = 2000
+			}
+			return data
+		},
+	},
+	AIOperationContinue: {
+		defaultContent: `请继续写下面这篇文章：
+
+{{.ExistingContent}}
+
+{{if .Direction}}续写方向：{{.Direction}}
+
+{{end}}要求：
+1. 保持与前文的风格和语气一致
+2. 使用Markdown格式
+3. 内容连贯自然
+4. 直接输出续写内容，不要重复已有内容
+5. 不要添加额外的解释或评论`,
+		newRequest: func() interface{} { return &ContinueWritingRequest{} },
+		data: func(req interface{}) interface{} {
+			return *req.(*ContinueWritingRequest)
+		},
+	},
+	AIOperationPolish: {
+		defaultContent: `请对下面的文章进行润色和改进：
+
+{{.Content}}
+
+润色要求：
+{{if eq .Style "professional"}}1. 使用专业、正式的语言风格
+{{else if eq .Style "casual"}}1. 使用轻松、易懂的语言风格
+{{else if eq .Style "academic"}}1. 使用学术、严谨的语言风格
+{{end}}2. 改善语言表达，使其更加流畅
+3. 优化文章结构和逻辑
+4. 修正语法和拼写错误
+5. 保持原有的Markdown格式
+6. 保留代码块和专业术语
+7. 直接输出润色后的完整文章`,
+		newRequest: func() interface{} { return &PolishArticleRequest{} },
+		data: func(req interface{}) interface{} {
+			data := *req.(*PolishArticleRequest)
+			if data.Style == "" {
+				data.Style = "professional"
+			}
+			return data
+		},
+	},
+	AIOperationExpand: {
+		defaultContent: `请将下面的大纲扩展为完整的文章：
+
+{{.Outline}}
+
+字数要求：约{{.WordCount}}字
+
+要求：
+1. 基于大纲的每个要点展开详细内容
+2. 使用Markdown格式，保持层级结构
+3. 补充细节、示例和解释
+4. 内容充实完整
+5. 直接输出完整文章`,
+		newRequest: func() interface{} { return &ExpandOutlineRequest{} },
+		data: func(req interface{}) interface{} {
+			data := *req.(*ExpandOutlineRequest)
+			if data.WordCount == 0 {
+				data.WordCount = 2000
+			}
+			return data
+		},
+	},
+	AIOperationSummarize: {
+		defaultContent: `请为下面的文章撰写一段摘要：
+
+{{if .Title}}标题：{{.Title}}
+
+{{end}}{{.Content}}
+
+字数要求：不超过{{.MaxLength}}字
+
+要求：
+1. 概括文章的核心内容和结论
+2. 使用与原文相同的语言
+3. 使用纯文本，不要使用Markdown格式
+4. 直接输出摘要内容，不要额外的解释`,
+		newRequest: func() interface{} { return &SummarizeArticleRequest{} },
+		data: func(req interface{}) interface{} {
+			data := *req.(*SummarizeArticleRequest)
+			if data.MaxLength == 0 {
+				data.MaxLength = 200
+			}
+			return data
+		},
+	},
+	AIOperationSuggest: {
+		defaultContent: `你是一位博客编辑。请阅读下面的文章草稿，为其推荐标题、摘要、标签和分类。
+
+{{if .Title}}当前标题：{{.Title}}
+
+{{end}}正文：
+{{.Content}}
+
+{{if .ExistingCategories}}已有分类：{{join .ExistingCategories "、"}}
+{{end}}{{if .ExistingTags}}已有标签：{{join .ExistingTags "、"}}
+{{end}}
+要求：
+1. 推荐3个标题，简洁准确，每个不超过30字
+2. 摘要不超过{{.SummaryLength}}字，使用纯文本
+3. 推荐3-5个标签和1-2个分类，优先从已有标签和分类中选择，确有必要时再提出新的
+4. 只输出JSON，不要使用代码块，不要额外的解释，格式如下：
+{"titles": ["标题1", "标题2", "标题3"], "summary": "摘要", "tags": ["标签1", "标签2"], "categories": ["分类1"]}`,
+		newRequest: func() interface{} { return &SuggestMetadataRequest{} },
+		data: func(req interface{}) interface{} {
+			data := *req.(*SuggestMetadataRequest)
+			// 长文只取开头部分，足够判断主题
+			data.Content = truncateString(data.Content, suggestContentLength)
+			if data.SummaryLength == 0 {
+				data.SummaryLength = 200
+			}
+			return data
+		},
+	},
+	AIOperationTranslate: {
+		defaultContent: `请将下面的内容从{{.SourceLanguageName}}翻译为{{.TargetLanguageName}}：
+
+{{.Content}}
+
+要求：
+1. 保持原有的Markdown格式，包括标题层级、列表、表格、链接和图片
+2. 代码块（` + "```" + `包裹的内容）和行内代码保持原样，不要翻译
+3. 链接地址、图片地址和HTML标签保持原样
+4. 专业术语使用目标语言的通用译法，没有通用译法时保留原文
+5. 译文准确、通顺，符合目标语言的表达习惯
+6. 直接输出译文，不要额外的解释`,
+		newRequest: func() interface{} { return &TranslateRequest{} },
+		data: func(req interface{}) interface{} {
+			r := *req.(*TranslateRequest)
+			return struct {
+				TranslateRequest
+				SourceLanguageName string
+				TargetLanguageName string
+			}{r, languageName(r.SourceLanguage), languageName(r.TargetLanguage)}
+		},
+	},
+	AIOperationAnswer: {
+		defaultContent: `你是这个博客的问答助手。请仅根据下面从博客文章中检索到的资料回答读者的问题。
+
+资料：
+{{range .Sources}}[{{.Index}}] 《{{.Title}}》{{if .Heading}} - {{.Heading}}{{end}}
+{{.Content}}
+
+{{end}}问题：{{.Question}}
+
+要求：
+1. 只使用资料中的信息，资料不足以回答时直接说明博客中没有相关内容，不要编造
+2. 在用到资料的句子后标注来源编号，如 [1]、[2]
+3. 使用与问题相同的语言回答
+4. 使用Markdown格式，简洁明了
+5. 直接输出回答，不要重复问题`,
+		newRequest: func() interface{} { return &AnswerQuestionRequest{} },
+		data: func(req interface{}) interface{} {
+			return *req.(*AnswerQuestionRequest)
+		},
+	},
+}
+
+func init() {
+	for name, def := range promptDefinitions {
+		def.defaultTemplate = template.Must(parsePromptTemplate(name, def.defaultContent))
+	}
+}
+
+// buildGeneratePrompt 构建生成文章的提示词
+func buildGeneratePrompt(req *GenerateArticleRequest) string {
+	return buildPrompt(AIOperationGenerate, req)
+}
+
+// buildContinuePrompt 构建续写提示词
+func buildContinuePrompt(req *ContinueWritingRequest) string {
+	return buildPrompt(AIOperationContinue, req)
+}
+
+// buildPolishPrompt 构建润色提示词
+func buildPolishPrompt(req *PolishArticleRequest) string {
+	return buildPrompt(AIOperationPolish, req)
+}
+
+// buildExpandPrompt 构建扩展大纲提示词
+func buildExpandPrompt(req *ExpandOutlineRequest) string {
+	return buildPrompt(AIOperationExpand, req)
+}
+
+// buildSummaryPrompt 构建生成摘要提示词
+func buildSummaryPrompt(req *SummarizeArticleRequest) string {
+	return buildPrompt(AIOperationSummarize, req)
+}
+
+// buildSuggestPrompt 构建推荐标题、标签和分类的提示词
+func buildSuggestPrompt(req *SuggestMetadataRequest) string {
+	return buildPrompt(AIOperationSuggest, req)
+}
+
+// buildTranslatePrompt 构建翻译提示词
+func buildTranslatePrompt(req *TranslateRequest) string {
+	return buildPrompt(AIOperationTranslate, req)
+}
+
+// buildAnswerPrompt 构建问答提示词
+func buildAnswerPrompt(req *AnswerQuestionRequest) string {
+	return buildPrompt(AIOperationAnswer, req)
+}
+
+// buildPrompt 使用启用的模板渲染提示词，模板不存在或渲染失败时使用内置模板
+func buildPrompt(name string, req interface{}) string {
+	def := promptDefinitions[name]
+	data := def.data(req)
+
+	if active, err := activePromptTemplate(name); err != nil {
+		log.Printf("读取提示词模板 %s 失败，使用内置模板: %v", name, err)
+	} else if active != nil {
+		prompt, err := renderPrompt(name, active.Content, data)
+		if err == nil {
+			return prompt
+		}
+		log.Printf("渲染提示词模板 %s v%d 失败，使用内置模板: %v", name, active.Version, err)
+	}
+
+	var buf bytes.Buffer
+	if err := def.defaultTemplate.Execute(&buf, data); err != nil {
+		// 内置模板在启动时已校验，正常不会失败
+		log.Printf("渲染内置提示词模板 %s 失败: %v", name, err)
+	}
+	return buf.String()
+}
+
+// activePromptTemplate 获取启用的模板，没有时返回 nil
+func activePromptTemplate(name string) (*models.PromptTemplate, error) {
+	if database.DB == nil {
+		return nil, nil
+	}
+	var tmpl models.PromptTemplate
+	err := database.DB.Where("name = ? AND active = ?", name, true).First(&tmpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// parsePromptTemplate 解析提示词模板
+func parsePromptTemplate(name, content string) (*template.Template, error) {
+	return template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(content)
+}
+
+// renderPrompt 使用模板内容渲染提示词
+func renderPrompt(name, content string, data interface{}) (string, error) {
+	tmpl, err := parsePromptTemplate(name, content)
+	if err != nil {
+		return "", fmt.Errorf("解析模板失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -1,5 +1,14 @@
 package services
 
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+)
+
 // GenerateArticleRequest 生成文章请求
 type GenerateArticleRequest struct {
 	Title     string   `json:"title"`
@@ -26,26 +35,208 @@ type ExpandOutlineRequest struct {
 	WordCount int    `json:"word_count"`
 }
 
-// AIService AI服务接口
+// SummarizeArticleRequest 生成摘要请求
+type SummarizeArticleRequest struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	MaxLength int    `json:"max_length"`
+}
+
+// SuggestMetadataRequest 推荐标题、摘要、标签和分类请求
+type SuggestMetadataRequest struct {
+	Title              string   `json:"title"`
+	Content            string   `json:"content"`
+	ExistingTags       []string `json:"existing_tags"`       // 已有标签，供模型优先选择
+	ExistingCategories []string `json:"existing_categories"` // 已有分类
+	SummaryLength      int      `json:"summary_length"`
+}
+
+// MetadataSuggestion 模型返回的推荐结果
+type MetadataSuggestion struct {
+	Titles     []string `json:"titles"`
+	Summary    string   `json:"summary"`
+	Tags       []string `json:"tags"`
+	Categories []string `json:"categories"`
+}
+
+// TranslateRequest 翻译请求
+type TranslateRequest struct {
+	Content        string `json:"content"`
+	SourceLanguage string `json:"source_language"`
+	TargetLanguage string `json:"target_language"`
+}
+
+// AnswerQuestionRequest 根据检索到的资料回答问题请求
+type AnswerQuestionRequest struct {
+	Question string         `json:"question"`
+	Sources  []AnswerSource `json:"sources"`
+}
+
 // AIService AI服务接口
 type AIService interface {
 	// GenerateArticle 生成文章初稿
-	GenerateArticle(req *GenerateArticleRequest) (string, error)
+	GenerateArticle(ctx context.Context, req *GenerateArticleRequest) (string, error)
 	// StreamGenerateArticle 流式生成文章初稿
-	StreamGenerateArticle(req *GenerateArticleRequest, callback func(string) error) error
+	StreamGenerateArticle(ctx context.Context, req *GenerateArticleRequest, callback func(string) error) error
 
 	// ContinueWriting 续写内容
-	ContinueWriting(req *ContinueWritingRequest) (string, error)
+	ContinueWriting(ctx context.Context, req *ContinueWritingRequest) (string, error)
 	// StreamContinueWriting 流式续写内容
-	StreamContinueWriting(req *ContinueWritingRequest, callback func(string) error) error
+	StreamContinueWriting(ctx context.Context, req *ContinueWritingRequest, callback func(string) error) error
 
 	// PolishArticle 润色文章
-	PolishArticle(req *PolishArticleRequest) (string, error)
+	PolishArticle(ctx context.Context, req *PolishArticleRequest) (string, error)
 	// StreamPolishArticle 流式润色文章
-	StreamPolishArticle(req *PolishArticleRequest, callback func(string) error) error
+	StreamPolishArticle(ctx context.Context, req *PolishArticleRequest, callback func(string) error) error
 
 	// ExpandOutline 大纲扩展
-	ExpandOutline(req *ExpandOutlineRequest) (string, error)
+	ExpandOutline(ctx context.Context, req *ExpandOutlineRequest) (string, error)
 	// StreamExpandOutline 流式扩展大纲
-	StreamExpandOutline(req *ExpandOutlineRequest, callback func(string) error) error
+	StreamExpandOutline(ctx context.Context, req *ExpandOutlineRequest, callback func(string) error) error
+
+	// SummarizeArticle 生成文章摘要
+	SummarizeArticle(ctx context.Context, req *SummarizeArticleRequest) (string, error)
+
+	// SuggestMetadata 推荐标题、摘要、标签和分类
+	SuggestMetadata(ctx context.Context, req *SuggestMetadataRequest) (*MetadataSuggestion, error)
+
+	// Translate 翻译内容，保留Markdown格式和代码块
+	Translate(ctx context.Context, req *TranslateRequest) (string, error)
+	// StreamTranslate 流式翻译内容
+	StreamTranslate(ctx context.Context, req *TranslateRequest, callback func(string) error) error
+
+	// AnswerQuestion 根据资料回答问题，标注引用编号
+	AnswerQuestion(ctx context.Context, req *AnswerQuestionRequest) (string, error)
+	// StreamAnswerQuestion 流式回答问题
+	StreamAnswerQuestion(ctx context.Context, req *AnswerQuestionRequest, callback func(string) error) error
+}
+
+// chatProvider 模型提供方，负责把提示词发送给具体的模型接口
+type chatProvider interface {
+	// complete 返回完整回复和接口报告的用量
+	complete(ctx context.Context, prompt string) (string, TokenUsage, error)
+	// stream 逐段返回回复内容，ctx 取消时中止上游请求；接口未报告用量时返回零值
+	stream(ctx context.Context, prompt string, callback func(string) error) (TokenUsage, error)
+}
+
+// NewAIService 根据配置创建AI服务
+// provider 可选 qwen（通义千问兼容模式）、openai（OpenAI 兼容接口）、ollama（本地模型）、fake（离线测试用）
+func NewAIService(cfg config.AIConfig) (AIService, error) {
+	idleTimeout := defaultAIIdleTimeout
+	if cfg.IdleTimeout > 0 {
+		idleTimeout = time.Duration(cfg.IdleTimeout) * time.Second
+	}
+
+	var provider chatProvider
+	switch strings.ToLower(cfg.Provider) {
+	case "", "qwen":
+		provider = newOpenAIProvider(cfg.Qwen.APIKey, cfg.Qwen.APIURL, cfg.Qwen.Model, cfg.Qwen.MaxTokens, cfg.Qwen.Temperature, idleTimeout)
+	case "openai":
+		provider = newOpenAIProvider(cfg.OpenAI.APIKey, cfg.OpenAI.APIURL, cfg.OpenAI.Model, cfg.OpenAI.MaxTokens, cfg.OpenAI.Temperature, idleTimeout)
+	case "ollama":
+		provider = newOllamaProvider(cfg.Ollama, idleTimeout)
+	case "fake":
+		provider = newFakeProvider(cfg.Fake)
+	default:
+		return nil, fmt.Errorf("不支持的AI服务提供方: %s", cfg.Provider)
+	}
+	return &promptService{provider: provider}, nil
+}
+
+// promptService 使用统一的提示词调用模型提供方，实现 AIService
+type promptService struct {
+	provider chatProvider
+}
+
+// StreamGenerateArticle 流式生成文章初稿
+func (s *promptService) StreamGenerateArticle(ctx context.Context, req *GenerateArticleRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationGenerate, buildGeneratePrompt(req), callback)
+}
+
+// GenerateArticle 生成文章初稿
+func (s *promptService) GenerateArticle(ctx context.Context, req *GenerateArticleRequest) (string, error) {
+	return s.complete(ctx, AIOperationGenerate, buildGeneratePrompt(req))
+}
+
+// StreamContinueWriting 流式续写内容
+func (s *promptService) StreamContinueWriting(ctx context.Context, req *ContinueWritingRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationContinue, buildContinuePrompt(req), callback)
+}
+
+// ContinueWriting 续写内容
+func (s *promptService) ContinueWriting(ctx context.Context, req *ContinueWritingRequest) (string, error) {
+	return s.complete(ctx, AIOperationContinue, buildContinuePrompt(req))
+}
+
+// StreamPolishArticle 流式润色文章
+func (s *promptService) StreamPolishArticle(ctx context.Context, req *PolishArticleRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationPolish, buildPolishPrompt(req), callback)
+}
+
+// PolishArticle 润色文章
+func (s *promptService) PolishArticle(ctx context.Context, req *PolishArticleRequest) (string, error) {
+	return s.complete(ctx, AIOperationPolish, buildPolishPrompt(req))
+}
+
+// StreamExpandOutline 流式扩展大纲
+func (s *promptService) StreamExpandOutline(ctx context.Context, req *ExpandOutlineRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationExpand, buildExpandPrompt(req), callback)
+}
+
+// ExpandOutline 扩展大纲
+func (s *promptService) ExpandOutline(ctx context.Context, req *ExpandOutlineRequest) (string, error) {
+	return s.complete(ctx, AIOperationExpand, buildExpandPrompt(req))
+}
+
+// SummarizeArticle 生成文章摘要
+func (s *promptService) SummarizeArticle(ctx context.Context, req *SummarizeArticleRequest) (string, error) {
+	return s.complete(ctx, AIOperationSummarize, buildSummaryPrompt(req))
+}
+
+// SuggestMetadata 推荐标题、摘要、标签和分类
+func (s *promptService) SuggestMetadata(ctx context.Context, req *SuggestMetadataRequest) (*MetadataSuggestion, error) {
+	content, err := s.complete(ctx, AIOperationSuggest, buildSuggestPrompt(req))
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadataSuggestion(content)
+}
+
+// StreamTranslate 流式翻译内容
+func (s *promptService) StreamTranslate(ctx context.Context, req *TranslateRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationTranslate, buildTranslatePrompt(req), callback)
+}
+
+// Translate 翻译内容
+func (s *promptService) Translate(ctx context.Context, req *TranslateRequest) (string, error) {
+	content, err := s.complete(ctx, AIOperationTranslate, buildTranslatePrompt(req))
+	return strings.TrimSpace(content), err
+}
+
+// StreamAnswerQuestion 流式回答问题
+func (s *promptService) StreamAnswerQuestion(ctx context.Context, req *AnswerQuestionRequest, callback func(string) error) error {
+	return s.stream(ctx, AIOperationAnswer, buildAnswerPrompt(req), callback)
+}
+
+// AnswerQuestion 回答问题
+func (s *promptService) AnswerQuestion(ctx context.Context, req *AnswerQuestionRequest) (string, error) {
+	return s.complete(ctx, AIOperationAnswer, buildAnswerPrompt(req))
+}
+
+// complete 调用提供方并记录用量
+func (s *promptService) complete(ctx context.Context, operation, prompt string) (string, error) {
+	content, usage, err := s.provider.complete(ctx, prompt)
+	recordAIUsage(ctx, operation, prompt, content, usage)
+	return content, err
+}
+
+// stream 流式调用提供方并记录用量，中途断开时按已输出的内容计算
+func (s *promptService) stream(ctx context.Context, operation, prompt string, callback func(string) error) error {
+	var output strings.Builder
+	usage, err := s.provider.stream(ctx, prompt, func(content string) error {
+		output.WriteString(content)
+		return callback(content)
+	})
+	recordAIUsage(ctx, operation, prompt, output.String(), usage)
+	return err
 }
@@ -0,0 +1,230 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/services"
+	"go-blog/internal/testutil"
+)
+
+func newFakeAIService(t *testing.T) services.AIService {
+	t.Helper()
+	ai, err := services.NewAIService(config.AIConfig{Provider: "fake", Fake: config.FakeAIConfig{ChunkSize: 4}})
+	if err != nil {
+		t.Fatalf("创建模拟AI服务失败: %v", err)
+	}
+	return ai
+}
+
+func TestStreamWithFakeProvider(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+	ctx := services.WithAIUser(context.Background(), 7)
+
+	var chunks []string
+	err := ai.StreamGenerateArticle(ctx, &services.GenerateArticleRequest{Title: "Go 并发"}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("流式生成失败: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("应分多段返回，实际 %d 段", len(chunks))
+	}
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if n := len([]rune(chunk)); n != 4 {
+			t.Errorf("每段应为4个字符，实际 %d: %q", n, chunk)
+		}
+	}
+
+	// 相同的提示词返回相同的内容
+	full, err := ai.GenerateArticle(ctx, &services.GenerateArticleRequest{Title: "Go 并发"})
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if got := strings.Join(chunks, ""); got != full {
+		t.Errorf("流式结果与完整结果不一致:\n%s\n%s", got, full)
+	}
+
+	var usage models.AIUsage
+	if err := database.DB.Where("user_id = ? AND operation = ?", 7, services.AIOperationGenerate).First(&usage).Error; err != nil {
+		t.Fatalf("未记录用量: %v", err)
+	}
+	if usage.Requests != 2 || usage.PromptTokens == 0 || usage.CompletionTokens == 0 {
+		t.Errorf("用量记录不正确: %+v", usage)
+	}
+}
+
+func TestStreamStopsWhenCallbackFails(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+
+	stop := errors.New("客户端断开")
+	calls := 0
+	err := ai.StreamPolishArticle(context.Background(), &services.PolishArticleRequest{Content: "需要润色的内容"}, func(string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("应返回回调的错误，实际 %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("回调出错后应停止输出，实际调用 %d 次", calls)
+	}
+}
+
+func TestSuggestArticleMetadataWithFakeProvider(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+	database.DB.Create(&models.Tag{Name: "标签1"})
+	database.DB.Create(&models.Category{Name: "分类1"})
+
+	// 模拟提供方原样返回提示词中的 JSON 示例
+	result, err := services.SuggestArticleMetadata(context.Background(), ai, "", "一篇关于 Go 的草稿")
+	if err != nil {
+		t.Fatalf("推荐失败: %v", err)
+	}
+
+	if strings.Join(result.Titles, ",") != "标题1,标题2,标题3" {
+		t.Errorf("标题不正确: %v", result.Titles)
+	}
+	if result.Summary != "摘要" {
+		t.Errorf("摘要不正确: %q", result.Summary)
+	}
+	if len(result.Tags) != 1 || result.Tags[0].Name != "标签1" {
+		t.Errorf("应匹配已有标签: %+v", result.Tags)
+	}
+	if strings.Join(result.NewTags, ",") != "标签2" {
+		t.Errorf("新标签不正确: %v", result.NewTags)
+	}
+	if len(result.Categories) != 1 || result.Categories[0].Name != "分类1" || len(result.NewCategories) != 0 {
+		t.Errorf("分类不正确: %+v %v", result.Categories, result.NewCategories)
+	}
+}
+
+func TestCheckAIQuota(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+
+	if err := services.CheckAIQuota(1); err != nil {
+		t.Fatalf("未配置上限时不应限制: %v", err)
+	}
+
+	config.AppConfig.AI.Quota.DailyTokens = 10
+	if err := services.CheckAIQuota(1); err != nil {
+		t.Fatalf("未使用时不应限制: %v", err)
+	}
+
+	ctx := services.WithAIUser(context.Background(), 1)
+	if _, err := ai.ExpandOutline(ctx, &services.ExpandOutlineRequest{Outline: "1. 背景\n2. 方案\n3. 总结"}); err != nil {
+		t.Fatalf("扩展大纲失败: %v", err)
+	}
+	if err := services.CheckAIQuota(1); !errors.Is(err, services.ErrAIQuotaExceeded) {
+		t.Fatalf("超出上限时应返回 ErrAIQuotaExceeded，实际 %v", err)
+	}
+	if err := services.CheckAIQuota(2); err != nil {
+		t.Errorf("其他用户不受影响: %v", err)
+	}
+}
+
+func TestCheckAnonymousAIQuota(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+	if err := services.CheckAnonymousAIQuota(); !errors.Is(err, services.ErrAnonymousAIDisabled) {
+		t.Fatalf("未配置访客上限时不应对访客开放，实际 %v", err)
+	}
+
+	config.AppConfig.AI.Quota.AnonymousDailyTokens = 10
+	outline := &services.ExpandOutlineRequest{Outline: "1. 背景\n2. 方案\n3. 总结"}
+
+	// 系统调用不占用访客的额度
+	if _, err := ai.ExpandOutline(context.Background(), outline); err != nil {
+		t.Fatalf("扩展大纲失败: %v", err)
+	}
+	if err := services.CheckAnonymousAIQuota(); err != nil {
+		t.Fatalf("系统调用不应计入访客用量: %v", err)
+	}
+
+	if _, err := ai.ExpandOutline(services.WithAnonymousAI(context.Background()), outline); err != nil {
+		t.Fatalf("扩展大纲失败: %v", err)
+	}
+	if err := services.CheckAnonymousAIQuota(); !errors.Is(err, services.ErrAIQuotaExceeded) {
+		t.Fatalf("超出访客上限时应返回 ErrAIQuotaExceeded，实际 %v", err)
+	}
+	var usage models.AIUsage
+	if err := database.DB.Where("user_id = 0 AND operation = ?", "anonymous_"+services.AIOperationExpand).First(&usage).Error; err != nil {
+		t.Errorf("访客用量应单独记录: %v", err)
+	}
+}
+
+func TestTranslationSave(t *testing.T) {
+	testutil.SetupDB(t)
+	ai := newFakeAIService(t)
+	author := testutil.CreateUser(t, "author")
+
+	category := models.Category{Name: "后端"}
+	database.DB.Create(&category)
+	original, err := services.CreateArticle(services.CreateArticleRequest{
+		Title:       "并发模式",
+		Content:     "## 通道\n\n使用通道传递数据。",
+		Status:      "published",
+		Language:    "zh",
+		CategoryIDs: []uint{category.ID},
+	}, author.ID)
+	if err != nil {
+		t.Fatalf("创建原文失败: %v", err)
+	}
+
+	translate := func() *models.Article {
+		t.Helper()
+		job, err := services.PrepareTranslation(original.ID, "EN")
+		if err != nil {
+			t.Fatalf("准备翻译失败: %v", err)
+		}
+		title, content, err := job.Translate(context.Background(), ai, func(string) error { return nil })
+		if err != nil {
+			t.Fatalf("翻译失败: %v", err)
+		}
+		article, err := job.Save(title, content, author.ID)
+		if err != nil {
+			t.Fatalf("保存译文失败: %v", err)
+		}
+		return article
+	}
+
+	first := translate()
+	if first.Status != "draft" || first.Language != "en" {
+		t.Errorf("译文应为英文草稿: status=%s language=%s", first.Status, first.Language)
+	}
+	if first.TranslationOfID == nil || *first.TranslationOfID != original.ID {
+		t.Errorf("译文应关联到原文: %v", first.TranslationOfID)
+	}
+	if len(first.Categories) != 1 || first.Categories[0].ID != category.ID {
+		t.Errorf("译文应沿用原文的分类: %+v", first.Categories)
+	}
+
+	// 再次翻译覆盖已有的译文草稿
+	second := translate()
+	if second.ID != first.ID {
+		t.Errorf("应覆盖已有译文草稿 %d，实际新建了 %d", first.ID, second.ID)
+	}
+
+	// 译文发布后不再覆盖
+	published := "published"
+	if _, err := services.UpdateArticle(first.ID, services.UpdateArticleRequest{Status: &published}, author.ID); err != nil {
+		t.Fatalf("发布译文失败: %v", err)
+	}
+	if _, err := services.PrepareTranslation(original.ID, "en"); err == nil {
+		t.Error("已发布的译文不应被覆盖")
+	}
+	if _, err := services.PrepareTranslation(original.ID, "zh"); err == nil {
+		t.Error("目标语言与原文相同时应返回错误")
+	}
+}
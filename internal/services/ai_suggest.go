@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// suggestContentLength 推荐元数据时发送给模型的正文长度上限（字符）
+const suggestContentLength = 6000
+
+// MetadataSuggestionResult 推荐结果，标签和分类已与现有数据匹配
+type MetadataSuggestionResult struct {
+	Titles        []string          `json:"titles"`
+	Summary       string            `json:"summary"`
+	Tags          []models.Tag      `json:"tags"`           // 匹配到的已有标签
+	NewTags       []string          `json:"new_tags"`       // 建议新建的标签
+	Categories    []models.Category `json:"categories"`     // 匹配到的已有分类
+	NewCategories []string          `json:"new_categories"` // 建议新建的分类
+}
+
+// SuggestArticleMetadata 根据草稿推荐标题、摘要、标签和分类
+// 已有的标签和分类会提供给模型优先选择，返回结果按名称（不区分大小写）与现有数据匹配，未匹配的作为新建建议单独返回
+func SuggestArticleMetadata(ctx context.Context, ai AIService, title, content string) (*MetadataSuggestionResult, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("内容不能为空")
+	}
+
+	var tags []models.Tag
+	if err := database.DB.Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	var categories []models.Category
+	if err := database.DB.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	req := &SuggestMetadataRequest{
+		Title:         title,
+		Content:       content,
+		SummaryLength: summaryMaxLength(),
+	}
+	tagsByName := make(map[string]models.Tag, len(tags))
+	for _, tag := range tags {
+		req.ExistingTags = append(req.ExistingTags, tag.Name)
+		tagsByName[metadataKey(tag.Name)] = tag
+	}
+	categoriesByName := make(map[string]models.Category, len(categories))
+	for _, category := range categories {
+		req.ExistingCategories = append(req.ExistingCategories, category.Name)
+		categoriesByName[metadataKey(category.Name)] = category
+	}
+
+	suggestion, err := ai.SuggestMetadata(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MetadataSuggestionResult{
+		Titles:        []string{},
+		Summary:       truncateAtSentence(strings.TrimSpace(suggestion.Summary), maxSummaryLength),
+		Tags:          []models.Tag{},
+		NewTags:       []string{},
+		Categories:    []models.Category{},
+		NewCategories: []string{},
+	}
+	for _, t := range suggestion.Titles {
+		if t = strings.TrimSpace(t); t != "" && !containsString(result.Titles, t) {
+			result.Titles = append(result.Titles, truncateString(t, 255))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range suggestion.Tags {
+		key := metadataKey(name)
+		if key == "" || seen["t:"+key] {
+			continue
+		}
+		seen["t:"+key] = true
+		if tag, ok := tagsByName[key]; ok {
+			result.Tags = append(result.Tags, tag)
+		} else {
+			result.NewTags = append(result.NewTags, truncateString(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "#")), 50))
+		}
+	}
+	for _, name := range suggestion.Categories {
+		key := metadataKey(name)
+		if key == "" || seen["c:"+key] {
+			continue
+		}
+		seen["c:"+key] = true
+		if category, ok := categoriesByName[key]; ok {
+			result.Categories = append(result.Categories, category)
+		} else {
+			result.NewCategories = append(result.NewCategories, truncateString(strings.TrimSpace(name), 50))
+		}
+	}
+
+	return result, nil
+}
+
+// metadataKey 标签和分类名称的匹配键：忽略大小写、首尾空白和 # 前缀
+func metadataKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "#")))
+}
+
+// parseMetadataSuggestion 解析模型返回的 JSON，兼容包裹在代码块或说明文字中的情况
+func parseMetadataSuggestion(content string) (*MetadataSuggestion, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("AI返回的内容无法解析")
+	}
+
+	var suggestion MetadataSuggestion
+	if err := json.Unmarshal([]byte(content[start:end+1]), &suggestion); err != nil {
+		return nil, errors.New("AI返回的内容无法解析")
+	}
+	return &suggestion, nil
+}
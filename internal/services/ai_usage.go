@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+	"unicode"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AI 操作类型，用于分别统计用量
+const (
+	AIOperationGenerate  = "generate"
+	AIOperationContinue  = "continue"
+	AIOperationPolish    = "polish"
+	AIOperationExpand    = "expand"
+	AIOperationSummarize = "summarize"
+	AIOperationSuggest   = "suggest"
+	AIOperationTranslate = "translate"
+	AIOperationAnswer    = "answer"
+	AIOperationEmbed     = "embed"
+)
+
+// anonymousOperationPrefix 未登录读者发起的操作在用量中加上前缀，与系统调用分开统计
+const anonymousOperationPrefix = "anonymous_"
+
+// ErrAIQuotaExceeded 用户的AI用量已达上限
+var ErrAIQuotaExceeded = errors.New("AI用量已达上限")
+
+// ErrAnonymousAIDisabled 未配置未登录读者的用量上限，不对未登录读者开放
+var ErrAnonymousAIDisabled = errors.New("请登录后使用AI功能")
+
+// TokenUsage 单次调用的 token 用量
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// aiUserKey 在 context 中保存调用AI的用户
+type aiUserKey struct{}
+
+// WithAIUser 记录发起AI调用的用户，用量计入该用户
+func WithAIUser(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, aiUserKey{}, userID)
+}
+
+// aiAnonymousKey 在 context 中标记未登录读者发起的调用
+type aiAnonymousKey struct{}
+
+// WithAnonymousAI 标记未登录读者发起的AI调用，用量单独统计并受匿名额度限制
+func WithAnonymousAI(ctx context.Context) context.Context {
+	return context.WithValue(ctx, aiAnonymousKey{}, true)
+}
+
+// aiUserFromContext 获取发起调用的用户，系统调用返回 0
+func aiUserFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(aiUserKey{}).(uint)
+	return userID
+}
+
+// recordAIUsage 累加到当日用量，接口未返回用量时按字符数估算
+func recordAIUsage(ctx context.Context, operation, prompt, output string, usage TokenUsage) {
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		// 请求失败且没有任何输出时不计量
+		if output == "" {
+			return
+		}
+		usage = estimateTokenUsage(prompt, output)
+	}
+	if anonymous, _ := ctx.Value(aiAnonymousKey{}).(bool); anonymous {
+		operation = anonymousOperationPrefix + operation
+	}
+
+	record := models.AIUsage{
+		Date:             time.Now().Format("2006-01-02"),
+		UserID:           aiUserFromContext(ctx),
+		Operation:        operation,
+		Requests:         1,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "date"}, {Name: "user_id"}, {Name: "operation"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":          gorm.Expr("requests + ?", 1),
+			"prompt_tokens":     gorm.Expr("prompt_tokens + ?", record.PromptTokens),
+			"completion_tokens": gorm.Expr("completion_tokens + ?", record.CompletionTokens),
+			"updated_at":        time.Now(),
+		}),
+	}).Create(&record).Error
+	if err != nil {
+		log.Printf("记录AI用量失败: %v", err)
+	}
+}
+
+// estimateTokenUsage 估算用量：中日韩字符每字约 1 个 token，其他字符约 4 个字符 1 个 token
+func estimateTokenUsage(prompt, output string) TokenUsage {
+	return TokenUsage{PromptTokens: estimateTokens(prompt), CompletionTokens: estimateTokens(output)}
+}
+
+// estimateTokens 估算文本的 token 数
+func estimateTokens(text string) int {
+	cjk, others := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			cjk++
+		case !unicode.IsSpace(r):
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// CheckAIQuota 检查用户当日和当月的用量是否已达上限，未配置上限时不限制
+func CheckAIQuota(userID uint) error {
+	quota := aiQuotaConfig()
+	if quota.DailyTokens <= 0 && quota.MonthlyTokens <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if quota.DailyTokens > 0 {
+		used, err := userTokensSince(userID, now.Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if used >= quota.DailyTokens {
+			return fmt.Errorf("%w：今日已使用 %d / %d tokens", ErrAIQuotaExceeded, used, quota.DailyTokens)
+		}
+	}
+	if quota.MonthlyTokens > 0 {
+		used, err := userTokensSince(userID, now.Format("2006-01")+"-01")
+		if err != nil {
+			return err
+		}
+		if used >= quota.MonthlyTokens {
+			return fmt.Errorf("%w：本月已使用 %d / %d tokens", ErrAIQuotaExceeded, used, quota.MonthlyTokens)
+		}
+	}
+	return nil
+}
+
+// CheckAnonymousAIQuota 检查未登录读者当日共用的用量是否已达上限，不包含系统调用
+// 未配置上限时不对未登录读者开放，避免公开接口无限消耗用量
+func CheckAnonymousAIQuota() error {
+	limit := aiQuotaConfig().AnonymousDailyTokens
+	if limit <= 0 {
+		return ErrAnonymousAIDisabled
+	}
+
+	var used int64
+	if err := database.DB.Model(&models.AIUsage{}).
+		Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0)").
+		Where("user_id = 0 AND operation LIKE ? AND date >= ?", anonymousOperationPrefix+"%", time.Now().Format("2006-01-02")).
+		Scan(&used).Error; err != nil {
+		return err
+	}
+	if used >= limit {
+		return fmt.Errorf("%w：今日访客已使用 %d / %d tokens", ErrAIQuotaExceeded, used, limit)
+	}
+	return nil
+}
+
+// userTokensSince 用户自某日起的 token 总数
+func userTokensSince(userID uint, since string) (int64, error) {
+	var total int64
+	err := database.DB.Model(&models.AIUsage{}).
+		Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0)").
+		Where("user_id = ? AND date >= ?", userID, since).
+		Scan(&total).Error
+	return total, err
+}
+
+// aiQuotaConfig 用量上限配置
+func aiQuotaConfig() config.AIQuotaConfig {
+	if config.AppConfig == nil {
+		return config.AIQuotaConfig{}
+	}
+	return config.AppConfig.AI.Quota
+}
+
+// AIUsageSummary 用量汇总
+type AIUsageSummary struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	EstimatedCost    float64 `json:"estimated_cost"`
+}
+
+// AIUserUsage 单个用户的用量
+type AIUserUsage struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"` // 系统调用为空
+	AIUsageSummary
+}
+
+// AIOperationUsage 单个操作的用量
+type AIOperationUsage struct {
+	Operation string `json:"operation"`
+	AIUsageSummary
+}
+
+// AIDailyUsage 每日用量
+type AIDailyUsage struct {
+	Date string `json:"date"`
+	AIUsageSummary
+}
+
+// AIUsageReport AI用量报告
+type AIUsageReport struct {
+	Days        int                  `json:"days"`
+	Currency    string               `json:"currency"`
+	Quota       config.AIQuotaConfig `json:"quota"`
+	Total       AIUsageSummary       `json:"total"`
+	ByUser      []AIUserUsage        `json:"by_user"`
+	ByOperation []AIOperationUsage   `json:"by_operation"`
+	Daily       []AIDailyUsage       `json:"daily"`
+}
+
+// aiUsageRow 聚合查询结果
+type aiUsageRow struct {
+	Key              string
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// GetAIUsageReport 获取最近 days 天的AI用量和估算费用，userID 不为 0 时只统计该用户
+func GetAIUsageReport(days int, userID uint) (*AIUsageReport, error) {
+	days = normalizeDays(days)
+	since := analyticsSince(days)
+
+	query := func(key string) ([]aiUsageRow, error) {
+		db := database.DB.Model(&models.AIUsage{}).
+			Select(key+" AS `key`, SUM(requests) AS requests, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens").
+			Where("date >= ?", since)
+		if userID != 0 {
+			db = db.Where("user_id = ?", userID)
+		}
+		var rows []aiUsageRow
+		err := db.Group(key).Order(key).Scan(&rows).Error
+		return rows, err
+	}
+
+	report := &AIUsageReport{
+		Days:        days,
+		Quota:       aiQuotaConfig(),
+		ByUser:      []AIUserUsage{},
+		ByOperation: []AIOperationUsage{},
+		Daily:       make([]AIDailyUsage, 0, days),
+	}
+	if config.AppConfig != nil {
+		report.Currency = config.AppConfig.AI.Pricing.Currency
+	}
+
+	rows, err := query("user_id")
+	if err != nil {
+		return nil, err
+	}
+	var total aiUsageRow
+	var userIDs []uint
+	for _, row := range rows {
+		total.Requests += row.Requests
+		total.PromptTokens += row.PromptTokens
+		total.CompletionTokens += row.CompletionTokens
+
+		id, _ := strconv.ParseUint(row.Key, 10, 64)
+		report.ByUser = append(report.ByUser, AIUserUsage{UserID: uint(id), AIUsageSummary: aiUsageSummary(row)})
+		userIDs = append(userIDs, uint(id))
+	}
+	report.Total = aiUsageSummary(total)
+
+	if len(userIDs) > 0 {
+		var users []models.User
+		if err := database.DB.Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		names := make(map[uint]string, len(users))
+		for _, user := range users {
+			names[user.ID] = user.Username
+		}
+		for i := range report.ByUser {
+			report.ByUser[i].Username = names[report.ByUser[i].UserID]
+		}
+	}
+
+	if rows, err = query("operation"); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		report.ByOperation = append(report.ByOperation, AIOperationUsage{Operation: row.Key, AIUsageSummary: aiUsageSummary(row)})
+	}
+
+	if rows, err = query("date"); err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]aiUsageRow, len(rows))
+	for _, row := range rows {
+		byDate[row.Key] = row
+	}
+	start := time.Now().AddDate(0, 0, -(days - 1))
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		report.Daily = append(report.Daily, AIDailyUsage{Date: date, AIUsageSummary: aiUsageSummary(byDate[date])})
+	}
+
+	return report, nil
+}
+
+// aiUsageSummary 计算合计和估算费用
+func aiUsageSummary(row aiUsageRow) AIUsageSummary {
+	summary := AIUsageSummary{
+		Requests:         row.Requests,
+		PromptTokens:     row.PromptTokens,
+		CompletionTokens: row.CompletionTokens,
+		TotalTokens:      row.PromptTokens + row.CompletionTokens,
+	}
+	if config.AppConfig != nil {
+		pricing := config.AppConfig.AI.Pricing
+		cost := float64(row.PromptTokens)/1000*pricing.PromptPrice + float64(row.CompletionTokens)/1000*pricing.CompletionPrice
+		summary.EstimatedCost = math.Round(cost*10000) / 10000
+	}
+	return summary
+}
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultAnalyticsFlushInterval = time.Minute
+
+var articlePathPattern = regexp.MustCompile(`^/article/(\d+)/?$`)
+
+// analyticsPaths 统计接受的页面路径（文章详情页另行校验），其他路径一律忽略，避免写入任意数据
+var analyticsPaths = map[string]bool{
+	"/":       true,
+	"/search": true,
+}
+
+// PageViewRequest 页面访问上报请求
+type PageViewRequest struct {
+	Path     string `json:"path" binding:"required"`
+	Referrer string `json:"referrer"`
+}
+
+// PageViewContext 页面访问的请求信息，仅用于计算，不会保存
+type PageViewContext struct {
+	IP        string
+	UserAgent string
+	Host      string
+}
+
+// pageViewKey 日统计聚合维度
+type pageViewKey struct {
+	Date         string
+	Path         string
+	ReferrerHost string
+	Country      string
+	Device       string
+}
+
+type pageViewCount struct {
+	ArticleID     uint
+	Views         int
+	Visitors      int
+	PathVisitors  int
+	DailyVisitors int
+}
+
+// keyVisitor 访客在某个聚合维度上的去重键
+type keyVisitor struct {
+	visitor string
+	key     pageViewKey
+}
+
+// pathVisitor 访客在某个路径上的去重键
+type pathVisitor struct {
+	visitor string
+	path    string
+}
+
+// AnalyticsCollector 无 Cookie 的页面访问统计
+// 访客仅以“每日轮换盐值 + IP + User-Agent”的哈希识别，盐值不落盘，次日即无法关联
+type AnalyticsCollector struct {
+	mu       sync.Mutex
+	geoIP    *GeoIPDB
+	pending  map[pageViewKey]*pageViewCount
+	day      string
+	salt     string
+	visitors map[string]bool      // 当日已访问过的访客
+	keyed    map[keyVisitor]bool  // 当日已计入各聚合维度的访客
+	pathed   map[pathVisitor]bool // 当日已访问过各路径的访客
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var analyticsCollector *AnalyticsCollector
+
+// InitAnalytics 初始化访问统计
+func InitAnalytics() {
+	cfg := config.AppConfig.Analytics
+	if !cfg.Enabled {
+		return
+	}
+
+	interval := defaultAnalyticsFlushInterval
+	if cfg.FlushInterval > 0 {
+		interval = time.Duration(cfg.FlushInterval) * time.Second
+	}
+
+	var geoIP *GeoIPDB
+	if cfg.GeoIPFile != "" {
+		db, err := LoadGeoIPDB(cfg.GeoIPFile)
+		if err != nil {
+			log.Printf("加载GeoIP数据失败，将不统计国家: %v", err)
+		} else {
+			geoIP = db
+		}
+	}
+
+	analyticsCollector = &AnalyticsCollector{
+		geoIP:    geoIP,
+		pending:  make(map[pageViewKey]*pageViewCount),
+		visitors: make(map[string]bool),
+		keyed:    make(map[keyVisitor]bool),
+		pathed:   make(map[pathVisitor]bool),
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go analyticsCollector.run()
+}
+
+// StopAnalytics 停止访问统计，并写入未保存的数据
+func StopAnalytics() {
+	if analyticsCollector == nil {
+		return
+	}
+	close(analyticsCollector.stop)
+	<-analyticsCollector.done
+}
+
+// RecordPageView 记录一次页面访问，返回是否计入统计
+func RecordPageView(req PageViewRequest, ctx PageViewContext) bool {
+	if analyticsCollector == nil || IsBot(ctx.UserAgent) {
+		return false
+	}
+
+	path := normalizePath(req.Path)
+	if path == "" {
+		return false
+	}
+
+	key := pageViewKey{
+		Date:         time.Now().Format("2006-01-02"),
+		Path:         path,
+		ReferrerHost: referrerHost(req.Referrer, ctx.Host),
+		Country:      analyticsCollector.geoIP.Lookup(ctx.IP),
+		Device:       deviceClass(ctx.UserAgent),
+	}
+
+	var articleID uint
+	if matches := articlePathPattern.FindStringSubmatch(path); matches != nil {
+		id, _ := strconv.ParseUint(matches[1], 10, 32)
+		articleID = uint(id)
+		if !publishedArticleExists(articleID) {
+			return false
+		}
+	} else if !analyticsPaths[path] && !analyticsPaths[strings.TrimSuffix(path, "/")] {
+		return false
+	}
+
+	analyticsCollector.record(key, articleID, ctx)
+	return true
+}
+
+// record 累加访问数据
+func (ac *AnalyticsCollector) record(key pageViewKey, articleID uint, ctx PageViewContext) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	// 跨天时轮换盐值，清空访客集合
+	if ac.day != key.Date {
+		ac.day = key.Date
+		ac.salt = randomToken(16)
+		ac.visitors = make(map[string]bool)
+		ac.keyed = make(map[keyVisitor]bool)
+		ac.pathed = make(map[pathVisitor]bool)
+	}
+
+	count, ok := ac.pending[key]
+	if !ok {
+		count = &pageViewCount{ArticleID: articleID}
+		ac.pending[key] = count
+	}
+	count.Views++
+
+	visitor := utils.Fingerprint(ac.salt, ctx.IP, ctx.UserAgent)
+	if !ac.visitors[visitor] {
+		ac.visitors[visitor] = true
+		count.DailyVisitors++
+	}
+	if p := (pathVisitor{visitor, key.Path}); !ac.pathed[p] {
+		ac.pathed[p] = true
+		count.PathVisitors++
+	}
+	if k := (keyVisitor{visitor, key}); !ac.keyed[k] {
+		ac.keyed[k] = true
+		count.Visitors++
+	}
+}
+
+// publishedArticleExists 文章是否存在且已发布
+func publishedArticleExists(id uint) bool {
+	var count int64
+	database.DB.Model(&models.Article{}).Where("id = ? AND status = ?", id, "published").Count(&count)
+	return count > 0
+}
+
+// run 定期写入统计数据
+func (ac *AnalyticsCollector) run() {
+	defer close(ac.done)
+
+	ticker := time.NewTicker(ac.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ac.Flush()
+		case <-ac.stop:
+			ac.Flush()
+			return
+		}
+	}
+}
+
+// Flush 将聚合的访问数据累加到日统计表
+func (ac *AnalyticsCollector) Flush() {
+	ac.mu.Lock()
+	pending := ac.pending
+	ac.pending = make(map[pageViewKey]*pageViewCount)
+	ac.mu.Unlock()
+
+	for key, count := range pending {
+		stat := models.PageViewStat{
+			Date:          key.Date,
+			Path:          key.Path,
+			ReferrerHost:  key.ReferrerHost,
+			Country:       key.Country,
+			Device:        key.Device,
+			ArticleID:     count.ArticleID,
+			Views:         count.Views,
+			Visitors:      count.Visitors,
+			PathVisitors:  count.PathVisitors,
+			DailyVisitors: count.DailyVisitors,
+		}
+
+		err := database.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "date"}, {Name: "path"}, {Name: "referrer_host"}, {Name: "country"}, {Name: "device"},
+			},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"views":          gorm.Expr("views + ?", count.Views),
+				"visitors":       gorm.Expr("visitors + ?", count.Visitors),
+				"path_visitors":  gorm.Expr("path_visitors + ?", count.PathVisitors),
+				"daily_visitors": gorm.Expr("daily_visitors + ?", count.DailyVisitors),
+			}),
+		}).Create(&stat).Error
+		if err != nil {
+			log.Printf("写入访问统计失败: %v", err)
+		}
+	}
+}
+
+// TrafficPoint 每日流量
+type TrafficPoint struct {
+	Date     string `json:"date"`
+	Views    int64  `json:"views"`
+	Visitors int64  `json:"visitors"`
+}
+
+// TopArticle 热门文章
+type TopArticle struct {
+	ArticleID uint   `json:"article_id"`
+	Title     string `json:"title"`
+	Views     int64  `json:"views"`
+	Visitors  int64  `json:"visitors"` // 每日访客数之和，同一访客当日多次访问只计一次
+}
+
+// TopReferrer 主要来源
+type TopReferrer struct {
+	ReferrerHost string `json:"referrer_host"` // 为空表示直接访问
+	Views        int64  `json:"views"`
+	Visitors     int64  `json:"visitors"`
+}
+
+// GetTraffic 获取最近 days 天的每日流量，无数据的日期补零
+func GetTraffic(days int) ([]TrafficPoint, error) {
+	days = normalizeDays(days)
+	since := analyticsSince(days)
+
+	var rows []TrafficPoint
+	err := database.DB.Model(&models.PageViewStat{}).
+		Select("date, SUM(views) AS views, SUM(daily_visitors) AS visitors").
+		Where("date >= ?", since).
+		Group("date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]TrafficPoint, len(rows))
+	for _, row := range rows {
+		byDate[row.Date] = row
+	}
+
+	points := make([]TrafficPoint, 0, days)
+	start := time.Now().AddDate(0, 0, -(days - 1))
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		point, ok := byDate[date]
+		if !ok {
+			point = TrafficPoint{Date: date}
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// GetTopArticles 获取最近 days 天访问量最高的文章
+func GetTopArticles(days, limit int) ([]TopArticle, error) {
+	days = normalizeDays(days)
+	limit = normalizeLimit(limit)
+
+	var rows []TopArticle
+	err := database.DB.Table("page_view_stats").
+		Select("page_view_stats.article_id, articles.title, SUM(page_view_stats.views) AS views, SUM(page_view_stats.path_visitors) AS visitors").
+		Joins("JOIN articles ON articles.id = page_view_stats.article_id").
+		Where("page_view_stats.article_id > 0 AND page_view_stats.date >= ?", analyticsSince(days)).
+		Group("page_view_stats.article_id, articles.title").
+		Order("views DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// GetTopReferrers 获取最近 days 天的主要来源
+func GetTopReferrers(days, limit int) ([]TopReferrer, error) {
+	days = normalizeDays(days)
+	limit = normalizeLimit(limit)
+
+	var rows []TopReferrer
+	err := database.DB.Model(&models.PageViewStat{}).
+		Select("referrer_host, SUM(views) AS views, SUM(visitors) AS visitors").
+		Where("date >= ?", analyticsSince(days)).
+		Group("referrer_host").
+		Order("views DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// normalizePath 规范化访问路径，去除查询参数和片段
+func normalizePath(path string) string {
+	path = strings.TrimSpace(path)
+	if u, err := url.Parse(path); err == nil {
+		path = u.Path
+	}
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "/api") {
+		return ""
+	}
+	if len(path) > 255 {
+		path = path[:255]
+	}
+	return path
+}
+
+// referrerHost 提取来源域名，站内跳转和无效来源视为直接访问
+func referrerHost(referrer, siteHost string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	site := siteHost
+	if h, _, found := strings.Cut(siteHost, ":"); found {
+		site = h
+	}
+	if host == strings.TrimPrefix(strings.ToLower(site), "www.") {
+		return ""
+	}
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
+
+// deviceClass 根据 User-Agent 判断设备类型
+func deviceClass(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return "tablet"
+	case strings.Contains(ua, "mobile") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
+
+// normalizeDays 统计天数限制在 1-365 天，默认30天
+func normalizeDays(days int) int {
+	if days <= 0 || days > 365 {
+		return 30
+	}
+	return days
+}
+
+// normalizeLimit 返回条数限制在 1-100 条，默认10条
+func normalizeLimit(limit int) int {
+	if limit <= 0 || limit > 100 {
+		return 10
+	}
+	return limit
+}
+
+// analyticsSince 获取统计起始日期
+func analyticsSince(days int) string {
+	return time.Now().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/testutil"
+)
+
+func TestTopArticlesCountsVisitorOncePerDay(t *testing.T) {
+	testutil.SetupDB(t)
+	author := testutil.CreateUser(t, "author")
+	article := models.Article{Title: "并发模式", Content: "正文", Status: "published", AuthorID: author.ID}
+	if err := database.DB.Create(&article).Error; err != nil {
+		t.Fatalf("创建文章失败: %v", err)
+	}
+
+	previous := analyticsCollector
+	analyticsCollector = &AnalyticsCollector{pending: make(map[pageViewKey]*pageViewCount)}
+	t.Cleanup(func() { analyticsCollector = previous })
+
+	path := PageViewRequest{Path: fmt.Sprintf("/article/%d/", article.ID)}
+	reader := PageViewContext{IP: "203.0.113.1", UserAgent: "Mozilla/5.0", Host: "blog.example.com"}
+	other := PageViewContext{IP: "203.0.113.2", UserAgent: "Mozilla/5.0", Host: "blog.example.com"}
+
+	// 同一访客当日从两个来源访问
+	for _, referrer := range []string{"https://news.example.com/", "https://search.example.org/"} {
+		path.Referrer = referrer
+		if !RecordPageView(path, reader) {
+			t.Fatal("访问应计入统计")
+		}
+	}
+	RecordPageView(path, other)
+	analyticsCollector.Flush()
+
+	top, err := GetTopArticles(7, 10)
+	if err != nil {
+		t.Fatalf("获取热门文章失败: %v", err)
+	}
+	if len(top) != 1 || top[0].Views != 3 || top[0].Visitors != 2 {
+		t.Errorf("应为 3 次访问、2 位访客: %+v", top)
+	}
+
+	referrers, err := GetTopReferrers(7, 10)
+	if err != nil {
+		t.Fatalf("获取来源失败: %v", err)
+	}
+	for _, r := range referrers {
+		if r.ReferrerHost == "news.example.com" && r.Visitors != 1 {
+			t.Errorf("来源的访客数按来源去重: %+v", r)
+		}
+	}
+}
@@ -3,7 +3,12 @@ package services
 import (
 	"errors"
 	"go-blog/internal/database"
+	"go-blog/internal/events"
 	"go-blog/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // ArticleListQuery 文章列表查询参数
@@ -14,6 +19,7 @@ type ArticleListQuery struct {
 	TagID      *uint  `form:"tag_id"`
 	Status     string `form:"status"`
 	ShowAll    bool   `form:"show_all"` // 是否显示所有状态（包括草稿）
+	Language   string `form:"lang"`     // 只显示该语言的文章，为空时不限
 }
 
 // ArticleListResponse 文章列表响应
@@ -26,12 +32,17 @@ type ArticleListResponse struct {
 
 // CreateArticleRequest 创建文章请求
 type CreateArticleRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Content     string `json:"content" binding:"required"`
-	Summary     string `json:"summary"`
-	CategoryIDs []uint `json:"category_ids"` // 改为多分类
-	TagIDs      []uint `json:"tag_ids"`
-	Status      string `json:"status"`
+	Title           string `json:"title" binding:"required"`
+	Content         string `json:"content" binding:"required"`
+	Summary         string `json:"summary"`
+	CategoryIDs     []uint `json:"category_ids"` // 改为多分类
+	TagIDs          []uint `json:"tag_ids"`
+	Status          string `json:"status"`
+	IsPinned        bool   `json:"is_pinned"`
+	IsFeatured      bool   `json:"is_featured"`
+	Weight          int    `json:"weight"`
+	Language        string `json:"language"`          // 语言代码，为空时使用站点默认语言
+	TranslationOfID *uint  `json:"translation_of_id"` // 作为译文关联到的原文
 }
 
 // UpdateArticleRequest 更新文章请求
@@ -42,6 +53,17 @@ type UpdateArticleRequest struct {
 	CategoryIDs []uint  `json:"category_ids"` // 改为多分类
 	TagIDs      []uint  `json:"tag_ids"`
 	Status      *string `json:"status"`
+	IsPinned    *bool   `json:"is_pinned"`
+	IsFeatured  *bool   `json:"is_featured"`
+	Weight      *int    `json:"weight"`
+	Language    *string `json:"language"`
+}
+
+// ArticleDetail 文章详情响应
+type ArticleDetail struct {
+	models.Article
+	Series       *SeriesNavigation    `json:"series"`       // 系列导航，不属于系列时为空
+	Translations []ArticleTranslation `json:"translations"` // 已发布的其他语言版本
 }
 
 // GetArticleList 获取文章列表
@@ -70,6 +92,9 @@ func GetArticleList(query ArticleListQuery) (*ArticleListResponse, error) {
 		db = db.Joins("JOIN article_tags ON article_tags.article_id = articles.id").
 			Where("article_tags.tag_id = ?", *query.TagID)
 	}
+	if query.Language != "" {
+		db = db.Where("articles.language = ?", strings.ToLower(query.Language))
+	}
 
 	// 总数
 	var total int64
@@ -79,13 +104,14 @@ func GetArticleList(query ArticleListQuery) (*ArticleListResponse, error) {
 	var articles []models.Article
 	offset := (query.Page - 1) * query.PageSize
 	err := db.Preload("Author").Preload("Categories").Preload("Tags").
-		Order("created_at DESC").
+		Order("articles.is_pinned DESC, articles.weight DESC, articles.created_at DESC").
 		Limit(query.PageSize).Offset(offset).
 		Find(&articles).Error
 
 	if err != nil {
 		return nil, err
 	}
+	AttachArticleReactions(articles)
 
 	return &ArticleListResponse{
 		Total:    total,
@@ -95,8 +121,34 @@ func GetArticleList(query ArticleListQuery) (*ArticleListResponse, error) {
 	}, nil
 }
 
+// GetFeaturedArticles 获取推荐文章（首页轮播）
+// language 不为空时只返回该语言的文章
+func GetFeaturedArticles(limit int, language string) ([]models.Article, error) {
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	db := database.DB.Where("status = ? AND is_featured = ?", "published", true)
+	if language != "" {
+		db = db.Where("language = ?", strings.ToLower(language))
+	}
+
+	var articles []models.Article
+	err := db.Preload("Author").Preload("Categories").Preload("Tags").
+		Order("weight DESC, created_at DESC").
+		Limit(limit).
+		Find(&articles).Error
+
+	if err != nil {
+		return nil, err
+	}
+	AttachArticleReactions(articles)
+
+	return articles, nil
+}
+
 // GetArticleByID 根据ID获取文章详情
-func GetArticleByID(id uint) (*models.Article, error) {
+func GetArticleByID(id uint) (*ArticleDetail, error) {
 	var article models.Article
 	err := database.DB.Preload("Author").Preload("Categories").Preload("Tags").
 		First(&article, id).Error
@@ -105,26 +157,60 @@ func GetArticleByID(id uint) (*models.Article, error) {
 		return nil, errors.New("文章不存在")
 	}
 
-	// 增加浏览量
-	database.DB.Model(&article).UpdateColumn("view_count", article.ViewCount+1)
+	// 加上尚未写入数据库的浏览量
+	article.ViewCount += PendingArticleViews(article.ID)
+	article.Reactions = reactionCounts(ReactionTargetArticle, []uint{article.ID})[article.ID]
 
-	return &article, nil
+	return &ArticleDetail{
+		Article:      article,
+		Series:       getSeriesNavigation(&article),
+		Translations: GetArticleTranslations(&article, true),
+	}, nil
 }
 
 // CreateArticle 创建文章
 func CreateArticle(req CreateArticleRequest, authorID uint) (*models.Article, error) {
+	language, err := articleLanguage(req.Language)
+	if err != nil {
+		return nil, err
+	}
+	translationOfID, err := resolveTranslationOf(req.TranslationOfID, language)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := analyzeContent(req.Content)
 	article := models.Article{
-		Title:    req.Title,
-		Content:  req.Content,
-		Summary:  req.Summary,
-		AuthorID: authorID,
-		Status:   req.Status,
+		Title:           req.Title,
+		Content:         req.Content,
+		Summary:         req.Summary,
+		AuthorID:        authorID,
+		Status:          req.Status,
+		IsPinned:        req.IsPinned,
+		IsFeatured:      req.IsFeatured,
+		Weight:          req.Weight,
+		Language:        language,
+		TranslationOfID: translationOfID,
+		TOC:             meta.TOC,
+		WordCount:       meta.WordCount,
+		ReadingTime:     meta.ReadingTime,
 	}
 
 	if article.Status == "" {
 		article.Status = "draft"
 	}
 
+	if article.Status == "published" {
+		now := time.Now()
+		article.PublishedAt = &now
+	}
+
+	// 未填写摘要时自动截取正文生成
+	if strings.TrimSpace(article.Summary) == "" {
+		article.Summary = GenerateSummary(article.Content, summaryMaxLength())
+		article.SummaryAuto = true
+	}
+
 	// 开始事务
 	tx := database.DB.Begin()
 
@@ -159,6 +245,15 @@ func CreateArticle(req CreateArticleRequest, authorID uint) (*models.Article, er
 	// 重新加载关联数据
 	database.DB.Preload("Author").Preload("Categories").Preload("Tags").First(&article, article.ID)
 
+	payload := events.ArticlePayload{
+		Article:   article,
+		Published: article.Status == "published",
+	}
+	events.Publish(events.ArticleCreated, payload)
+	if payload.Published {
+		events.Publish(events.ArticlePublished, payload)
+	}
+
 	return &article, nil
 }
 
@@ -181,13 +276,60 @@ func UpdateArticle(id uint, req UpdateArticleRequest, authorID uint) (*models.Ar
 	}
 	if req.Content != nil {
 		updates["content"] = *req.Content
-	}
-	if req.Summary != nil {
-		updates["summary"] = *req.Summary
+
+		// 内容变化时重新生成目录、字数和阅读时长
+		meta := analyzeContent(*req.Content)
+		updates["toc"] = meta.TOC
+		updates["word_count"] = meta.WordCount
+		updates["reading_time"] = meta.ReadingTime
 	}
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
+	if req.IsPinned != nil {
+		updates["is_pinned"] = *req.IsPinned
+	}
+	if req.IsFeatured != nil {
+		updates["is_featured"] = *req.IsFeatured
+	}
+	if req.Weight != nil {
+		updates["weight"] = *req.Weight
+	}
+	if req.Language != nil {
+		language, err := NormalizeLanguage(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		if language != article.Language {
+			if err := checkTranslationLanguage(translationGroupID(&article), language, article.ID); err != nil {
+				return nil, err
+			}
+		}
+		updates["language"] = language
+	}
+
+	// 摘要：手动填写时保留；清空或正文变化（且原摘要为自动生成）时重新截取
+	summaryAuto := article.SummaryAuto
+	summaryRegenerated := false
+	content := article.Content
+	if req.Content != nil {
+		content = *req.Content
+	}
+	if req.Summary != nil && strings.TrimSpace(*req.Summary) != "" {
+		updates["summary"] = *req.Summary
+		summaryAuto = false
+	} else if req.Summary != nil || (req.Content != nil && article.SummaryAuto) {
+		updates["summary"] = GenerateSummary(content, summaryMaxLength())
+		summaryAuto = true
+		summaryRegenerated = true
+	}
+	updates["summary_auto"] = summaryAuto
+
+	wasPublished := article.Status == "published"
+	newlyPublished := req.Status != nil && *req.Status == "published" && !wasPublished
+	if newlyPublished && article.PublishedAt == nil {
+		updates["published_at"] = time.Now()
+	}
 
 	tx := database.DB.Begin()
 
@@ -221,6 +363,17 @@ func UpdateArticle(id uint, req UpdateArticleRequest, authorID uint) (*models.Ar
 	// 重新加载
 	database.DB.Preload("Author").Preload("Categories").Preload("Tags").First(&article, id)
 
+	payload := events.ArticlePayload{
+		Article:            article,
+		Published:          newlyPublished,
+		WasPublished:       wasPublished,
+		SummaryRegenerated: summaryRegenerated,
+	}
+	events.Publish(events.ArticleUpdated, payload)
+	if newlyPublished {
+		events.Publish(events.ArticlePublished, payload)
+	}
+
 	return &article, nil
 }
 
@@ -236,5 +389,22 @@ func DeleteArticle(id uint, authorID uint) error {
 		return errors.New("无权限删除此文章")
 	}
 
-	return database.DB.Delete(&article).Error
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := DeleteReactions(tx, ReactionTargetArticle, article.ID); err != nil {
+			return err
+		}
+		if err := detachTranslations(tx, &article); err != nil {
+			return err
+		}
+		if err := deleteArticleEmbedding(tx, article.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&article).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.ArticleDeleted, events.ArticlePayload{Article: article, WasPublished: article.Status == "published"})
+	return nil
 }
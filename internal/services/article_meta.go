@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// cjkCharsPerMinute 中日韩文字每分钟阅读字数
+	cjkCharsPerMinute = 400
+	// wordsPerMinute 其他语言每分钟阅读词数
+	wordsPerMinute = 200
+)
+
+var (
+	// 结尾的 # 前必须有空白才是闭合序列，如 "## Learn C#" 中的 # 属于标题文字
+	headingPattern    = regexp.MustCompile(`^(#{1,6})\s+(.+?)(?:\s+#+)?\s*$`)
+	inlineLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	inlineMarkPattern = regexp.MustCompile("[*_`~]+")
+)
+
+// articleMeta 由文章内容派生的元数据
+type articleMeta struct {
+	TOC         models.ArticleTOC
+	WordCount   int
+	ReadingTime int
+}
+
+// analyzeContent 分析Markdown内容，生成目录、字数和阅读时长
+func analyzeContent(content string) articleMeta {
+	cjk, words := countWords(content)
+	return articleMeta{
+		TOC:         buildTOC(content),
+		WordCount:   cjk + words,
+		ReadingTime: estimateReadingTime(cjk, words),
+	}
+}
+
+// buildTOC 根据Markdown标题生成目录，忽略代码块中的内容
+func buildTOC(content string) models.ArticleTOC {
+	toc := models.ArticleTOC{}
+	used := make(map[string]int)
+
+	for _, line := range scanMarkdown(content) {
+		if line.Code {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line.Text)
+		matches := headingPattern.FindStringSubmatch(trimmed)
+		if matches == nil {
+			continue
+		}
+
+		text := stripInlineMarkdown(matches[2])
+		if text == "" {
+			continue
+		}
+
+		// 锚点规则与 GitHub 保持一致，重复时追加序号
+		anchor := slugify(text)
+		if n, ok := used[anchor]; ok {
+			used[anchor] = n + 1
+			anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+		} else {
+			used[anchor] = 0
+		}
+
+		toc = append(toc, models.TOCItem{
+			Level:  len(matches[1]),
+			Text:   text,
+			Anchor: anchor,
+		})
+	}
+
+	return toc
+}
+
+// countWords 统计字数，返回中日韩字符数和其他语言单词数，代码块不计入
+func countWords(content string) (cjk int, words int) {
+	for _, line := range scanMarkdown(content) {
+		if line.Code {
+			continue
+		}
+		inWord := false
+		for _, r := range line.Text {
+			switch {
+			case isCJK(r):
+				cjk++
+				inWord = false
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if !inWord {
+					words++
+					inWord = true
+				}
+			case r == '\'' || r == '-':
+				// 单词内部的撇号和连字符不拆分单词
+			default:
+				inWord = false
+			}
+		}
+	}
+	return cjk, words
+}
+
+// markdownLine Markdown 中的一行
+type markdownLine struct {
+	Text string
+	Code bool // 属于围栏代码块（含围栏行）或缩进代码块
+}
+
+// scanMarkdown 逐行标记代码块
+// 缩进四个空格或制表符的行，前面是空行或标题且不在列表中时视为缩进代码块
+func scanMarkdown(content string) []markdownLine {
+	lines := strings.Split(content, "\n")
+	result := make([]markdownLine, len(lines))
+	inFence, inIndented, inList := false, false, false
+	blockStart := true
+
+	for i, line := range lines {
+		result[i].Text = line
+		trimmed := strings.TrimSpace(line)
+		indented := strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+		isFence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+
+		switch {
+		case inFence:
+			result[i].Code = true
+			if isFence {
+				inFence = false
+				blockStart = true
+			}
+			continue
+		case trimmed == "":
+			blockStart = true
+			continue
+		case indented && (inIndented || (blockStart && !inList)):
+			result[i].Code = true
+			inIndented = true
+			continue
+		}
+
+		inIndented = false
+		blockStart = false
+		switch {
+		case isFence:
+			result[i].Code = true
+			inFence = true
+		case listPrefixRegexp.MatchString(trimmed):
+			inList = true
+		case headingPattern.MatchString(trimmed):
+			inList = false
+			blockStart = true
+		case !indented:
+			inList = false
+		}
+	}
+	return result
+}
+
+// RefreshArticleMeta 重新计算所有文章的目录、字数和阅读时长，用于规则变化后回填已有文章
+// 只更新有变化的文章，不修改更新时间，返回更新的文章数
+func RefreshArticleMeta() (int, error) {
+	updated := 0
+	var articles []models.Article
+	err := database.DB.Select("id", "content", "toc", "word_count", "reading_time").
+		FindInBatches(&articles, 100, func(tx *gorm.DB, batch int) error {
+			for _, article := range articles {
+				meta := analyzeContent(article.Content)
+				if meta.WordCount == article.WordCount && meta.ReadingTime == article.ReadingTime && slices.Equal(meta.TOC, article.TOC) {
+					continue
+				}
+				err := database.DB.Model(&models.Article{}).Where("id = ?", article.ID).UpdateColumns(map[string]interface{}{
+					"toc":          meta.TOC,
+					"word_count":   meta.WordCount,
+					"reading_time": meta.ReadingTime,
+				}).Error
+				if err != nil {
+					return err
+				}
+				updated++
+			}
+			return nil
+		}).Error
+	return updated, err
+}
+
+// estimateReadingTime 估算阅读时长（分钟），至少为1分钟
+func estimateReadingTime(cjk, words int) int {
+	if cjk == 0 && words == 0 {
+		return 0
+	}
+	minutes := float64(cjk)/cjkCharsPerMinute + float64(words)/wordsPerMinute
+	result := int(minutes + 0.999)
+	if result < 1 {
+		result = 1
+	}
+	return result
+}
+
+// isCJK 判断是否为中日韩字符
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// stripInlineMarkdown 去除行内Markdown标记
+func stripInlineMarkdown(text string) string {
+	text = inlineLinkPattern.ReplaceAllString(text, "$1")
+	text = inlineMarkPattern.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// slugify 生成标题锚点
+func slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// askChunkLength 分段的最大长度（字符），按段落切分，超长段落单独成段并截断
+	askChunkLength = 600
+	// askSourceLimit 回答问题时使用的分段数
+	askSourceLimit = 5
+	// askChunksPerArticle 每篇文章最多使用的分段数，避免资料集中在一篇文章
+	askChunksPerArticle = 2
+	// AskQuestionMaxLength 问题的最大长度（字符）
+	AskQuestionMaxLength = 500
+)
+
+// askNoSourceAnswer 没有检索到相关内容时的固定回答，不调用模型
+const askNoSourceAnswer = "抱歉，博客中没有找到与这个问题相关的内容。"
+
+// AnswerSource 回答问题引用的资料，Index 与回答中的 [n] 对应
+type AnswerSource struct {
+	Index     int     `json:"index"`
+	ArticleID uint    `json:"article_id"`
+	Title     string  `json:"title"`
+	Heading   string  `json:"heading"`
+	URL       string  `json:"url"`
+	Score     float64 `json:"score"`
+	Content   string  `json:"content"` // 分段内容
+}
+
+// articleChunk 分段结果
+type articleChunk struct {
+	Heading string
+	Anchor  string
+	Content string
+}
+
+// chunkArticle 按章节和段落将文章切分为不超过 askChunkLength 的分段
+// 代码块、表格等不参与分段；锚点与文章目录一致，用于引用时定位到章节
+func chunkArticle(content string) []articleChunk {
+	toc := buildTOC(content)
+	var chunks []articleChunk
+	var heading, anchor string
+	var section []string
+	headingIndex := 0
+
+	flushSection := func() {
+		var current []string
+		length := 0
+		flush := func() {
+			if len(current) > 0 {
+				chunks = append(chunks, articleChunk{Heading: heading, Anchor: anchor, Content: strings.Join(current, "\n")})
+				current = nil
+				length = 0
+			}
+		}
+		for _, paragraph := range markdownParagraphs(strings.Join(section, "\n")) {
+			// 超长段落按长度拆开
+			runes := []rune(paragraph)
+			for len(runes) > 0 {
+				piece := runes[:min(len(runes), askChunkLength)]
+				runes = runes[len(piece):]
+				if length > 0 && length+len(piece) > askChunkLength {
+					flush()
+				}
+				current = append(current, string(piece))
+				length += len(piece)
+			}
+		}
+		flush()
+		section = nil
+	}
+
+	for _, line := range scanMarkdown(content) {
+		if line.Code {
+			// 代码块不参与分段，保留为空行以免前后段落相连
+			section = append(section, "")
+			continue
+		}
+		// 与 buildTOC 的规则一致，按顺序对应目录项
+		if matches := headingPattern.FindStringSubmatch(strings.TrimSpace(line.Text)); matches != nil && stripInlineMarkdown(matches[2]) != "" {
+			flushSection()
+			if headingIndex < len(toc) {
+				heading, anchor = toc[headingIndex].Text, toc[headingIndex].Anchor
+			}
+			headingIndex++
+			continue
+		}
+		section = append(section, line.Text)
+	}
+	flushSection()
+
+	return chunks
+}
+
+// chunkEmbeddingText 计算分段向量使用的文本，带上文章标题和章节标题作为上下文
+func chunkEmbeddingText(title string, chunk articleChunk) string {
+	if chunk.Heading != "" {
+		title += " - " + chunk.Heading
+	}
+	return title + "\n" + chunk.Content
+}
+
+// indexArticleChunks 重新切分文章并计算各分段的向量，标题、正文和模型都未变化时跳过
+// 返回是否重新分段
+func indexArticleChunks(ctx context.Context, article *models.Article, full bool) (bool, error) {
+	hash := textHash(article.Title + "\n" + article.Content)
+	if !full {
+		var existing models.ArticleChunk
+		if err := database.DB.Select("id", "model", "content_hash").
+			Where("article_id = ?", article.ID).Limit(1).Find(&existing).Error; err != nil {
+			return false, err
+		}
+		if existing.ID != 0 && existing.ContentHash == hash && existing.Model == embedder.modelName() {
+			return false, nil
+		}
+	}
+
+	chunks := chunkArticle(article.Content)
+	rows := make([]models.ArticleChunk, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		texts := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			texts = append(texts, chunkEmbeddingText(article.Title, chunk))
+		}
+		vectors, err := embedTexts(ctx, texts)
+		if err != nil {
+			return false, err
+		}
+		for i, chunk := range chunks[start:end] {
+			rows = append(rows, models.ArticleChunk{
+				ArticleID:   article.ID,
+				Position:    start + i,
+				Heading:     truncateString(chunk.Heading, 255),
+				Anchor:      truncateString(chunk.Anchor, 255),
+				Content:     chunk.Content,
+				Model:       embedder.modelName(),
+				ContentHash: hash,
+				Vector:      vectors[i],
+			})
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleChunk{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	InvalidateEmbeddingCache()
+	return true, nil
+}
+
+// chunkVectors 当前模型计算的分段向量
+var chunkVectors = &vectorCache{load: func() ([]cachedVector, error) {
+	var rows []models.ArticleChunk
+	if err := database.DB.Select("id", "article_id", "vector").
+		Where("model = ?", embedder.modelName()).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	items := make([]cachedVector, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, cachedVector{ID: row.ID, ArticleID: row.ArticleID, Vector: row.Vector})
+	}
+	return items, nil
+}}
+
+// RetrieveAnswerSources 检索与问题最相关的已发布文章分段，language 不为空时只检索该语言的文章
+func RetrieveAnswerSources(ctx context.Context, question, language string) ([]AnswerSource, error) {
+	if !EmbeddingEnabled() {
+		return nil, ErrEmbeddingDisabled
+	}
+
+	query, err := embedQuery(ctx, question)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := chunkVectors.get()
+	if err != nil {
+		return nil, err
+	}
+	published, err := publishedArticleIDs(language)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		id        uint
+		articleID uint
+		score     float64
+	}
+	var candidates []scored
+	for _, v := range vectors {
+		if !published[v.ArticleID] {
+			continue
+		}
+		if score := dotProduct(query, v.Vector); score > 0 && score >= embeddingMinScore {
+			candidates = append(candidates, scored{id: v.ID, articleID: v.ArticleID, score: score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	perArticle := make(map[uint]int)
+	var selected []scored
+	for _, c := range candidates {
+		if perArticle[c.articleID] >= askChunksPerArticle {
+			continue
+		}
+		perArticle[c.articleID]++
+		selected = append(selected, c)
+		if len(selected) >= askSourceLimit {
+			break
+		}
+	}
+	if len(selected) == 0 {
+		return []AnswerSource{}, nil
+	}
+
+	ids := make([]uint, len(selected))
+	articleIDs := make([]uint, 0, len(perArticle))
+	for i, c := range selected {
+		ids[i] = c.id
+	}
+	for id := range perArticle {
+		articleIDs = append(articleIDs, id)
+	}
+	var chunks []models.ArticleChunk
+	if err := database.DB.Select("id", "article_id", "heading", "anchor", "content").
+		Where("id IN ?", ids).Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	var articles []models.Article
+	if err := database.DB.Select("id", "title").Where("id IN ?", articleIDs).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	chunksByID := make(map[uint]models.ArticleChunk, len(chunks))
+	for _, chunk := range chunks {
+		chunksByID[chunk.ID] = chunk
+	}
+	titles := make(map[uint]string, len(articles))
+	for _, article := range articles {
+		titles[article.ID] = article.Title
+	}
+
+	sources := make([]AnswerSource, 0, len(selected))
+	for _, c := range selected {
+		chunk, ok := chunksByID[c.id]
+		if !ok {
+			continue
+		}
+		url := articleURL(chunk.ArticleID)
+		if chunk.Anchor != "" {
+			url += "#" + chunk.Anchor
+		}
+		sources = append(sources, AnswerSource{
+			Index:     len(sources) + 1,
+			ArticleID: chunk.ArticleID,
+			Title:     titles[chunk.ArticleID],
+			Heading:   chunk.Heading,
+			URL:       url,
+			Score:     math.Round(c.score*10000) / 10000,
+			Content:   chunk.Content,
+		})
+	}
+	return sources, nil
+}
+
+// AskBlog 根据博客文章回答问题：检索相关分段，通过 onSources 返回引用的资料，再流式输出带引用编号的回答
+// 没有检索到相关内容时直接返回固定回答，不调用模型
+func AskBlog(ctx context.Context, ai AIService, question, language string, onSources func([]AnswerSource) error, callback func(string) error) error {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return errors.New("问题不能为空")
+	}
+
+	sources, err := RetrieveAnswerSources(ctx, question, language)
+	if err != nil {
+		return err
+	}
+	if err := onSources(sources); err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return callback(askNoSourceAnswer)
+	}
+
+	return ai.StreamAnswerQuestion(ctx, &AnswerQuestionRequest{
+		Question: question,
+		Sources:  sources,
+	}, callback)
+}
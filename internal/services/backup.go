@@ -0,0 +1,601 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+)
+
+const backupVersion = 1
+
+const (
+	// maxBackupEntrySize 恢复时单个文件解压后的大小上限
+	maxBackupEntrySize = 200 << 20
+	// maxBackupTotalSize 恢复时所有文件解压后的合计大小上限
+	maxBackupTotalSize = 2 << 30
+)
+
+// 备份中文章的存储格式
+const (
+	BackupFormatJSON     = "json"     // 全部文章保存在 articles.json
+	BackupFormatMarkdown = "markdown" // 每篇文章一个带 Front Matter 的 Markdown 文件
+)
+
+// BackupManifest 备份清单
+type BackupManifest struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	ArticleFormat string    `json:"article_format"`
+	Users         int       `json:"users"`
+	Categories    int       `json:"categories"`
+	Tags          int       `json:"tags"`
+	Series        int       `json:"series"`
+	Articles      int       `json:"articles"`
+	Comments      int       `json:"comments"`
+	Settings      int       `json:"settings"`
+	MediaFiles    int       `json:"media_files"`
+}
+
+// backupUser 备份中的用户，包含密码哈希以便恢复后直接登录
+type backupUser struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// backupArticle 备份中的文章，Markdown 格式时除正文外的字段写入 Front Matter
+type backupArticle struct {
+	ID               uint       `json:"id" yaml:"id"`
+	Title            string     `json:"title" yaml:"title"`
+	Summary          string     `json:"summary" yaml:"summary"`
+	SummaryAuto      bool       `json:"summary_auto" yaml:"summary_auto"`
+	AuthorID         uint       `json:"author_id" yaml:"author_id"`
+	Status           string     `json:"status" yaml:"status"`
+	Language         string     `json:"language" yaml:"language"`
+	TranslationOfID  *uint      `json:"translation_of_id" yaml:"translation_of_id"`
+	SeriesID         *uint      `json:"series_id" yaml:"series_id"`
+	SeriesOrder      int        `json:"series_order" yaml:"series_order"`
+	ViewCount        int        `json:"view_count" yaml:"view_count"`
+	IsPinned         bool       `json:"is_pinned" yaml:"is_pinned"`
+	IsFeatured       bool       `json:"is_featured" yaml:"is_featured"`
+	Weight           int        `json:"weight" yaml:"weight"`
+	CategoryIDs      []uint     `json:"category_ids" yaml:"category_ids"`
+	TagIDs           []uint     `json:"tag_ids" yaml:"tag_ids"`
+	PublishedAt      *time.Time `json:"published_at" yaml:"published_at"`
+	NewsletterSentAt *time.Time `json:"newsletter_sent_at" yaml:"newsletter_sent_at"`
+	CreatedAt        time.Time  `json:"created_at" yaml:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" yaml:"updated_at"`
+	Content          string     `json:"content" yaml:"-"`
+}
+
+// ExportBackup 导出全站备份（zip），包含用户、分类、标签、系列、文章、评论、设置和媒体文件
+func ExportBackup(w io.Writer, articleFormat string) (*BackupManifest, error) {
+	if articleFormat == "" {
+		articleFormat = BackupFormatJSON
+	}
+	if articleFormat != BackupFormatJSON && articleFormat != BackupFormatMarkdown {
+		return nil, fmt.Errorf("不支持的文章格式: %s", articleFormat)
+	}
+
+	var users []models.User
+	var categories []models.Category
+	var tags []models.Tag
+	var series []models.Series
+	var articles []models.Article
+	var comments []models.Comment
+	var settings []models.Setting
+
+	db := database.DB
+	for _, err := range []error{
+		db.Order("id ASC").Find(&users).Error,
+		db.Order("id ASC").Find(&categories).Error,
+		db.Order("id ASC").Find(&tags).Error,
+		db.Order("id ASC").Find(&series).Error,
+		db.Preload("Categories").Preload("Tags").Order("id ASC").Find(&articles).Error,
+		db.Order("id ASC").Find(&comments).Error,
+		db.Order("id ASC").Find(&settings).Error,
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &BackupManifest{
+		Version:       backupVersion,
+		CreatedAt:     time.Now(),
+		ArticleFormat: articleFormat,
+		Users:         len(users),
+		Categories:    len(categories),
+		Tags:          len(tags),
+		Series:        len(series),
+		Articles:      len(articles),
+		Comments:      len(comments),
+		Settings:      len(settings),
+	}
+
+	archive := zip.NewWriter(w)
+
+	backupUsers := make([]backupUser, len(users))
+	for i, user := range users {
+		backupUsers[i] = backupUser{
+			ID:        user.ID,
+			Username:  user.Username,
+			Password:  user.Password,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"users.json", backupUsers},
+		{"categories.json", categories},
+		{"tags.json", tags},
+		{"series.json", series},
+		{"comments.json", comments},
+		{"settings.json", settings},
+	}
+	for _, file := range files {
+		if err := writeBackupJSON(archive, file.name, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	backupArticles := make([]backupArticle, len(articles))
+	for i := range articles {
+		backupArticles[i] = toBackupArticle(&articles[i])
+	}
+	if articleFormat == BackupFormatJSON {
+		if err := writeBackupJSON(archive, "articles.json", backupArticles); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, article := range backupArticles {
+			content, err := marshalBackupMarkdown(&article)
+			if err != nil {
+				return nil, err
+			}
+			if err := writeBackupFile(archive, fmt.Sprintf("articles/%d.md", article.ID), content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	mediaFiles, err := writeBackupMedia(archive)
+	if err != nil {
+		return nil, err
+	}
+	manifest.MediaFiles = mediaFiles
+
+	if err := writeBackupJSON(archive, "manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// RestoreBackup 从备份恢复到空数据库
+// 目标库不能已有文章和评论；启动时创建的默认管理员、分类等种子数据会被备份内容替换
+func RestoreBackup(r io.ReaderAt, size int64) (*BackupManifest, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份文件失败: %w", err)
+	}
+	archive := &backupArchive{Reader: reader, remaining: maxBackupTotalSize}
+
+	var manifest BackupManifest
+	if err := readBackupJSON(archive, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version > backupVersion {
+		return nil, fmt.Errorf("不支持的备份版本: %d", manifest.Version)
+	}
+
+	var articleCount, commentCount int64
+	database.DB.Model(&models.Article{}).Count(&articleCount)
+	database.DB.Model(&models.Comment{}).Count(&commentCount)
+	if articleCount > 0 || commentCount > 0 {
+		return nil, errors.New("目标数据库已有文章或评论，只能恢复到空数据库")
+	}
+
+	var users []backupUser
+	var categories []models.Category
+	var tags []models.Tag
+	var series []models.Series
+	var comments []models.Comment
+	var settings []models.Setting
+	for name, target := range map[string]interface{}{
+		"users.json":      &users,
+		"categories.json": &categories,
+		"tags.json":       &tags,
+		"series.json":     &series,
+		"comments.json":   &comments,
+		"settings.json":   &settings,
+	} {
+		if err := readBackupJSON(archive, name, target); err != nil {
+			return nil, err
+		}
+	}
+
+	articles, err := readBackupArticles(archive, manifest.ArticleFormat)
+	if err != nil {
+		return nil, err
+	}
+	// 语言代码会用作静态站点的目录名，必须校验
+	for i := range articles {
+		language, err := NormalizeLanguage(articles[i].Language)
+		if err != nil {
+			return nil, fmt.Errorf("文章《%s》: %w", articles[i].Title, err)
+		}
+		articles[i].Language = language
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 清除种子数据
+		for _, model := range []interface{}{&models.User{}, &models.Category{}, &models.Tag{}, &models.Series{}, &models.Setting{}} {
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, user := range users {
+			if err := tx.Create(&models.User{
+				ID:        user.ID,
+				Username:  user.Username,
+				Password:  user.Password,
+				Email:     user.Email,
+				Role:      user.Role,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			}).Error; err != nil {
+				return fmt.Errorf("恢复用户失败: %w", err)
+			}
+		}
+		if err := createInBatches(tx, categories); err != nil {
+			return fmt.Errorf("恢复分类失败: %w", err)
+		}
+		if err := createInBatches(tx, tags); err != nil {
+			return fmt.Errorf("恢复标签失败: %w", err)
+		}
+		for i := range series {
+			series[i].Articles = nil
+		}
+		if err := createInBatches(tx, series); err != nil {
+			return fmt.Errorf("恢复系列失败: %w", err)
+		}
+
+		for i := range articles {
+			if err := restoreArticle(tx, &articles[i]); err != nil {
+				return err
+			}
+		}
+
+		// 按ID顺序恢复，被回复的评论总是先于回复
+		sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+		if err := createInBatches(tx, comments); err != nil {
+			return fmt.Errorf("恢复评论失败: %w", err)
+		}
+		if err := createInBatches(tx, settings); err != nil {
+			return fmt.Errorf("恢复设置失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := restoreBackupMedia(archive); err != nil {
+		return nil, err
+	}
+
+	InvalidateRelatedCache()
+	return &manifest, nil
+}
+
+// restoreArticle 恢复文章及其分类、标签关联，目录和字数重新计算
+func restoreArticle(tx *gorm.DB, item *backupArticle) error {
+	meta := analyzeContent(item.Content)
+	article := models.Article{
+		ID:               item.ID,
+		Title:            item.Title,
+		Content:          item.Content,
+		Summary:          item.Summary,
+		SummaryAuto:      item.SummaryAuto,
+		AuthorID:         item.AuthorID,
+		Status:           item.Status,
+		Language:         item.Language,
+		TranslationOfID:  item.TranslationOfID,
+		SeriesID:         item.SeriesID,
+		SeriesOrder:      item.SeriesOrder,
+		ViewCount:        item.ViewCount,
+		IsPinned:         item.IsPinned,
+		IsFeatured:       item.IsFeatured,
+		Weight:           item.Weight,
+		TOC:              meta.TOC,
+		WordCount:        meta.WordCount,
+		ReadingTime:      meta.ReadingTime,
+		PublishedAt:      item.PublishedAt,
+		NewsletterSentAt: item.NewsletterSentAt,
+		CreatedAt:        item.CreatedAt,
+		UpdatedAt:        item.UpdatedAt,
+	}
+	if err := tx.Create(&article).Error; err != nil {
+		return fmt.Errorf("恢复文章《%s》失败: %w", item.Title, err)
+	}
+
+	for _, categoryID := range item.CategoryIDs {
+		if err := tx.Table("article_categories").Create(map[string]interface{}{
+			"article_id":  article.ID,
+			"category_id": categoryID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	for _, tagID := range item.TagIDs {
+		if err := tx.Table("article_tags").Create(map[string]interface{}{
+			"article_id": article.ID,
+			"tag_id":     tagID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createInBatches 批量插入，空列表时跳过
+func createInBatches[T any](tx *gorm.DB, records []T) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(records, 100).Error
+}
+
+// toBackupArticle 转换为备份格式
+func toBackupArticle(article *models.Article) backupArticle {
+	item := backupArticle{
+		ID:               article.ID,
+		Title:            article.Title,
+		Summary:          article.Summary,
+		SummaryAuto:      article.SummaryAuto,
+		AuthorID:         article.AuthorID,
+		Status:           article.Status,
+		Language:         article.Language,
+		TranslationOfID:  article.TranslationOfID,
+		SeriesID:         article.SeriesID,
+		SeriesOrder:      article.SeriesOrder,
+		ViewCount:        article.ViewCount,
+		IsPinned:         article.IsPinned,
+		IsFeatured:       article.IsFeatured,
+		Weight:           article.Weight,
+		CategoryIDs:      []uint{},
+		TagIDs:           []uint{},
+		PublishedAt:      article.PublishedAt,
+		NewsletterSentAt: article.NewsletterSentAt,
+		CreatedAt:        article.CreatedAt,
+		UpdatedAt:        article.UpdatedAt,
+		Content:          article.Content,
+	}
+	for _, category := range article.Categories {
+		item.CategoryIDs = append(item.CategoryIDs, category.ID)
+	}
+	for _, tag := range article.Tags {
+		item.TagIDs = append(item.TagIDs, tag.ID)
+	}
+	return item
+}
+
+// marshalBackupMarkdown 生成带 YAML Front Matter 的 Markdown 文件
+func marshalBackupMarkdown(article *backupArticle) ([]byte, error) {
+	header, err := yaml.Marshal(article)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(header)
+	buf.WriteString("---\n\n")
+	buf.WriteString(article.Content)
+	if !strings.HasSuffix(article.Content, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// readBackupArticles 读取 JSON 或 Markdown 格式的文章
+func readBackupArticles(archive *backupArchive, format string) ([]backupArticle, error) {
+	var articles []backupArticle
+	if format != BackupFormatMarkdown {
+		if err := readBackupJSON(archive, "articles.json", &articles); err != nil {
+			return nil, err
+		}
+		return articles, nil
+	}
+
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "articles/") || path.Ext(file.Name) != ".md" {
+			continue
+		}
+		content, err := archive.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		text := strings.ReplaceAll(string(content), "\r\n", "\n")
+		if !strings.HasPrefix(text, "---\n") {
+			return nil, fmt.Errorf("%s: 缺少 Front Matter", file.Name)
+		}
+		end := strings.Index(text[4:], "\n---\n")
+		if end < 0 {
+			return nil, fmt.Errorf("%s: Front Matter 未闭合", file.Name)
+		}
+
+		var article backupArticle
+		if err := yaml.Unmarshal([]byte(text[4:4+end]), &article); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		article.Content = strings.TrimPrefix(text[4+end+5:], "\n")
+		articles = append(articles, article)
+	}
+
+	sort.Slice(articles, func(i, j int) bool { return articles[i].ID < articles[j].ID })
+	return articles, nil
+}
+
+// writeBackupMedia 打包媒体目录，返回文件数
+func writeBackupMedia(archive *zip.Writer) (int, error) {
+	dir := config.AppConfig.Backup.MediaDir
+	if dir == "" {
+		return 0, nil
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	count := 0
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		count++
+		return writeBackupFile(archive, "media/"+filepath.ToSlash(rel), content)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("打包媒体文件失败: %w", err)
+	}
+	return count, nil
+}
+
+// restoreBackupMedia 恢复媒体文件，未配置媒体目录时跳过
+func restoreBackupMedia(archive *backupArchive) error {
+	dir := config.AppConfig.Backup.MediaDir
+	if dir == "" {
+		return nil
+	}
+
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "media/") || strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		// 防止路径穿越
+		rel := path.Clean(strings.TrimPrefix(file.Name, "media/"))
+		if rel == "." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			continue
+		}
+
+		content, err := archive.readFile(file)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("恢复媒体文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeBackupJSON 写入 JSON 文件
+func writeBackupJSON(archive *zip.Writer, name string, data interface{}) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeBackupFile(archive, name, content)
+}
+
+// writeBackupFile 写入文件
+func writeBackupFile(archive *zip.Writer, name string, content []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// readBackupJSON 读取 JSON 文件
+func readBackupJSON(archive *backupArchive, name string, target interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		content, err := archive.readFile(file)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, target); err != nil {
+			return fmt.Errorf("解析 %s 失败: %w", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("备份文件缺少 %s", name)
+}
+
+// backupArchive 恢复时读取的备份压缩包，记录剩余可解压的字节数
+type backupArchive struct {
+	*zip.Reader
+	remaining int64
+}
+
+// readFile 读取压缩包中的文件，超过单文件上限或剩余的合计额度时返回错误
+// 按实际解压的字节计算，不信任压缩包中记录的文件大小
+func (a *backupArchive) readFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	limit := min(int64(maxBackupEntrySize), a.remaining)
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		if limit < maxBackupEntrySize {
+			return nil, fmt.Errorf("备份文件解压后合计超过%dGB", maxBackupTotalSize>>30)
+		}
+		return nil, fmt.Errorf("%s: 文件超过%dMB", file.Name, maxBackupEntrySize>>20)
+	}
+	a.remaining -= int64(len(content))
+	return content, nil
+}
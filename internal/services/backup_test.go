@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/services"
+	"go-blog/internal/testutil"
+)
+
+// backupWithArticles 生成只包含指定文章的备份
+func backupWithArticles(t *testing.T, articlesJSON string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := map[string]string{
+		"manifest.json":   `{"version": 1, "article_format": "json"}`,
+		"users.json":      `[{"id": 1, "username": "admin", "password": "x", "email": "admin@example.com", "role": "author"}]`,
+		"categories.json": `[]`,
+		"tags.json":       `[]`,
+		"series.json":     `[]`,
+		"comments.json":   `[]`,
+		"settings.json":   `[]`,
+		"articles.json":   articlesJSON,
+	}
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestRestoreBackupValidatesLanguage(t *testing.T) {
+	testutil.SetupDB(t)
+
+	r := backupWithArticles(t, `[{"id": 1, "title": "穿越", "author_id": 1, "status": "published", "language": "..", "content": "正文"}]`)
+	if _, err := services.RestoreBackup(r, r.Size()); err == nil || !strings.Contains(err.Error(), "无效的语言代码") {
+		t.Fatalf("无效的语言代码应拒绝恢复，实际 %v", err)
+	}
+	var count int64
+	database.DB.Model(&models.Article{}).Count(&count)
+	if count != 0 {
+		t.Errorf("拒绝恢复时不应写入文章，实际 %d 篇", count)
+	}
+
+	r = backupWithArticles(t, `[{"id": 1, "title": "Hello", "author_id": 1, "status": "published", "language": "EN_us", "content": "正文"}]`)
+	if _, err := services.RestoreBackup(r, r.Size()); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	var article models.Article
+	database.DB.First(&article, 1)
+	if article.Language != "en-us" {
+		t.Errorf("语言代码应规范化为 en-us，实际 %q", article.Language)
+	}
+}
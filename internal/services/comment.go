@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+
+	"go-blog/internal/database"
+	"go-blog/internal/events"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateComment 发表评论
+func CreateComment(comment *models.Comment) error {
+	// 验证文章是否存在
+	var article models.Article
+	if err := database.DB.First(&article, comment.ArticleID).Error; err != nil {
+		return errors.New("文章不存在")
+	}
+
+	// 回复的评论必须属于同一篇文章
+	if comment.ParentID != nil {
+		var parent models.Comment
+		if err := database.DB.First(&parent, *comment.ParentID).Error; err != nil || parent.ArticleID != comment.ArticleID {
+			return errors.New("回复的评论不存在")
+		}
+	}
+
+	if err := database.DB.Create(comment).Error; err != nil {
+		return errors.New("发表评论失败")
+	}
+
+	events.Publish(events.CommentCreated, events.CommentPayload{Comment: *comment})
+	return nil
+}
+
+// DeleteComment 删除评论及其表态，回复保留
+func DeleteComment(id uint) error {
+	var comment models.Comment
+	if err := database.DB.First(&comment, id).Error; err != nil {
+		return errors.New("评论不存在")
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := DeleteReactions(tx, ReactionTargetComment, comment.ID); err != nil {
+			return err
+		}
+		// 解除回复与被删除评论的关联
+		if err := tx.Model(&models.Comment{}).Where("parent_id = ?", comment.ID).
+			Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&comment).Error
+	})
+	if err != nil {
+		return errors.New("删除评论失败")
+	}
+
+	events.Publish(events.CommentDeleted, events.CommentPayload{Comment: comment})
+	return nil
+}
@@ -0,0 +1,561 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// embeddingTextLength 计算文章向量时使用的文本长度上限（字符）
+	embeddingTextLength       = 6000
+	defaultEmbeddingBatchSize = 10
+	// semanticResultLimit 语义搜索和混合搜索最多返回的结果数
+	semanticResultLimit = 100
+	// hybridRankConstant 混合搜索按排名融合（RRF）的平滑常数
+	hybridRankConstant = 60
+	// queryEmbeddingCacheSize 缓存的搜索词向量数，避免重复请求向量接口
+	queryEmbeddingCacheSize = 256
+)
+
+// SemanticKeywordMaxLength 语义搜索词的长度上限（字符），超出部分不参与计算向量
+const SemanticKeywordMaxLength = 100
+
+// ErrEmbeddingDisabled 未配置向量模型
+var ErrEmbeddingDisabled = errors.New("未启用语义搜索")
+
+var (
+	embedder           embeddingProvider
+	embeddingBatchSize = defaultEmbeddingBatchSize
+	embeddingMinScore  float64
+)
+
+// InitEmbedding 按配置初始化向量模型，未配置提供方时不启用语义搜索
+func InitEmbedding(cfg config.AIConfig) error {
+	idleTimeout := defaultAIIdleTimeout
+	if cfg.IdleTimeout > 0 {
+		idleTimeout = time.Duration(cfg.IdleTimeout) * time.Second
+	}
+
+	provider, err := newEmbeddingProvider(cfg, idleTimeout)
+	if err != nil {
+		return err
+	}
+	embedder = provider
+	embeddingBatchSize = defaultEmbeddingBatchSize
+	if cfg.Embedding.BatchSize > 0 {
+		embeddingBatchSize = cfg.Embedding.BatchSize
+	}
+	embeddingMinScore = cfg.Embedding.MinScore
+
+	InvalidateEmbeddingCache()
+	return nil
+}
+
+// EmbeddingEnabled 是否启用语义搜索
+func EmbeddingEnabled() bool {
+	return embedder != nil
+}
+
+// embedTexts 计算向量并记录用量，返回单位向量（余弦相似度即为点积）
+func embedTexts(ctx context.Context, texts []string) ([]models.Vector, error) {
+	raw, usage, err := embedder.embed(ctx, texts)
+	input := strings.Join(texts, "\n")
+	if err == nil && usage.PromptTokens == 0 {
+		usage.PromptTokens = estimateTokens(input)
+	}
+	recordAIUsage(ctx, AIOperationEmbed, input, "", usage)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([]models.Vector, len(raw))
+	for i, v := range raw {
+		vectors[i] = normalizeVector(v)
+	}
+	return vectors, nil
+}
+
+// normalizeVector 归一化为单位向量，零向量保持不变
+func normalizeVector(v []float32) models.Vector {
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+	result := make(models.Vector, len(v))
+	if norm == 0 {
+		copy(result, v)
+		return result
+	}
+	norm = math.Sqrt(norm)
+	for i, f := range v {
+		result[i] = float32(float64(f) / norm)
+	}
+	return result
+}
+
+// dotProduct 两个向量的点积，维度不同时返回 0
+func dotProduct(a, b models.Vector) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// embeddingText 计算文章向量使用的纯文本：标题、摘要和正文
+func embeddingText(article *models.Article) string {
+	text := article.Title + "\n" + article.Summary + "\n" + strings.Join(markdownParagraphs(article.Content), "\n")
+	return truncateString(text, embeddingTextLength)
+}
+
+// textHash 文本的 SHA-256
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// IndexArticleEmbedding 计算已发布文章及其分段的向量，文本和模型未变化时跳过；文章未发布或不存在时删除向量
+func IndexArticleEmbedding(ctx context.Context, articleID uint) error {
+	if !EmbeddingEnabled() {
+		return ErrEmbeddingDisabled
+	}
+
+	var article models.Article
+	err := database.DB.Where("status = ?", "published").First(&article, articleID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return deleteArticleEmbedding(database.DB, articleID)
+	}
+	if err != nil {
+		return err
+	}
+
+	text := embeddingText(&article)
+	hash := textHash(text)
+	var existing models.ArticleEmbedding
+	if err := database.DB.Select("article_id", "model", "content_hash").
+		Where("article_id = ?", articleID).Limit(1).Find(&existing).Error; err != nil {
+		return err
+	}
+	if existing.ContentHash != hash || existing.Model != embedder.modelName() {
+		vectors, err := embedTexts(ctx, []string{text})
+		if err != nil {
+			return err
+		}
+		if err := saveArticleEmbedding(articleID, hash, vectors[0]); err != nil {
+			return err
+		}
+	}
+
+	_, err = indexArticleChunks(ctx, &article, false)
+	return err
+}
+
+// EmbeddingReport 批量计算向量的结果
+type EmbeddingReport struct {
+	Indexed   int      `json:"indexed"`   // 重新计算的文章数
+	Unchanged int      `json:"unchanged"` // 未变化而跳过的文章数
+	Removed   int      `json:"removed"`   // 已下线而删除的向量数
+	Chunked   int      `json:"chunked"`   // 重新分段的文章数
+	Failed    []string `json:"failed"`    // 计算失败的批次
+}
+
+// ReindexEmbeddings 为所有已发布文章及其分段计算向量，full 为 true 时忽略已有结果全部重新计算
+func ReindexEmbeddings(ctx context.Context, full bool) (*EmbeddingReport, error) {
+	if !EmbeddingEnabled() {
+		return nil, ErrEmbeddingDisabled
+	}
+
+	var articles []models.Article
+	if err := database.DB.Select("id", "title", "summary", "content").
+		Where("status = ?", "published").
+		Order("id ASC").
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	var existing []models.ArticleEmbedding
+	if err := database.DB.Select("article_id", "model", "content_hash").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	hashes := make(map[uint]string, len(existing))
+	for _, e := range existing {
+		if e.Model == embedder.modelName() {
+			hashes[e.ArticleID] = e.ContentHash
+		}
+	}
+
+	report := &EmbeddingReport{Failed: []string{}}
+	type pendingArticle struct {
+		id   uint
+		text string
+		hash string
+	}
+	var pending []pendingArticle
+	published := make([]uint, 0, len(articles))
+	for i := range articles {
+		published = append(published, articles[i].ID)
+		text := embeddingText(&articles[i])
+		hash := textHash(text)
+		if !full && hashes[articles[i].ID] == hash {
+			report.Unchanged++
+			continue
+		}
+		pending = append(pending, pendingArticle{id: articles[i].ID, text: text, hash: hash})
+	}
+
+	for start := 0; start < len(pending); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		texts := make([]string, len(batch))
+		for i, p := range batch {
+			texts[i] = p.text
+		}
+		vectors, err := embedTexts(ctx, texts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			report.Failed = append(report.Failed, fmt.Sprintf("文章 %d-%d: %v", batch[0].id, batch[len(batch)-1].id, err))
+			continue
+		}
+		for i, p := range batch {
+			if err := saveArticleEmbedding(p.id, p.hash, vectors[i]); err != nil {
+				return report, err
+			}
+			report.Indexed++
+		}
+	}
+
+	for i := range articles {
+		chunked, err := indexArticleChunks(ctx, &articles[i], full)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			report.Failed = append(report.Failed, fmt.Sprintf("文章 %d 分段: %v", articles[i].ID, err))
+			continue
+		}
+		if chunked {
+			report.Chunked++
+		}
+	}
+
+	removed := func(db *gorm.DB) *gorm.DB {
+		if len(published) > 0 {
+			return db.Where("article_id NOT IN ?", published)
+		}
+		return db.Where("1 = 1")
+	}
+	result := removed(database.DB).Delete(&models.ArticleEmbedding{})
+	if result.Error != nil {
+		return report, result.Error
+	}
+	report.Removed = int(result.RowsAffected)
+	if err := removed(database.DB).Delete(&models.ArticleChunk{}).Error; err != nil {
+		return report, err
+	}
+	InvalidateEmbeddingCache()
+
+	return report, nil
+}
+
+// saveArticleEmbedding 保存文章的向量
+func saveArticleEmbedding(articleID uint, hash string, vector models.Vector) error {
+	err := database.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.ArticleEmbedding{
+		ArticleID:   articleID,
+		Model:       embedder.modelName(),
+		ContentHash: hash,
+		Dimensions:  len(vector),
+		Vector:      vector,
+	}).Error
+	if err != nil {
+		return err
+	}
+	InvalidateEmbeddingCache()
+	return nil
+}
+
+// deleteArticleEmbedding 删除文章及其分段的向量
+func deleteArticleEmbedding(tx *gorm.DB, articleID uint) error {
+	if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleEmbedding{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleChunk{}).Error; err != nil {
+		return err
+	}
+	InvalidateEmbeddingCache()
+	return nil
+}
+
+// cachedVector 缓存的向量，ID 为文章或分段的ID
+type cachedVector struct {
+	ID        uint
+	ArticleID uint
+	Vector    models.Vector
+}
+
+// vectorCache 按需从数据库加载的向量缓存，数据变化时整体失效
+type vectorCache struct {
+	mu     sync.RWMutex
+	items  []cachedVector
+	loaded bool
+	// generation 每次失效时递增，加载期间缓存失效则丢弃加载结果
+	generation uint64
+	load       func() ([]cachedVector, error)
+}
+
+// invalidate 清空缓存，下次读取时重新加载
+func (c *vectorCache) invalidate() {
+	c.mu.Lock()
+	c.items = nil
+	c.loaded = false
+	c.generation++
+	c.mu.Unlock()
+}
+
+// get 获取全部向量，未加载时从数据库加载
+func (c *vectorCache) get() ([]cachedVector, error) {
+	c.mu.RLock()
+	if c.loaded {
+		items := c.items
+		c.mu.RUnlock()
+		return items, nil
+	}
+	generation := c.generation
+	c.mu.RUnlock()
+
+	items, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	// 加载期间数据已变化时不写入缓存，本次结果仍可使用
+	if c.generation == generation {
+		c.items = items
+		c.loaded = true
+	}
+	c.mu.Unlock()
+	return items, nil
+}
+
+var (
+	// articleVectors 当前模型计算的文章向量
+	articleVectors = &vectorCache{load: func() ([]cachedVector, error) {
+		var rows []models.ArticleEmbedding
+		if err := database.DB.Select("article_id", "vector").
+			Where("model = ?", embedder.modelName()).
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		items := make([]cachedVector, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, cachedVector{ID: row.ArticleID, ArticleID: row.ArticleID, Vector: row.Vector})
+		}
+		return items, nil
+	}}
+
+	queryEmbeddingMu sync.Mutex
+	queryEmbeddings  = make(map[string]models.Vector)
+)
+
+// InvalidateEmbeddingCache 清空文章和分段的向量缓存
+func InvalidateEmbeddingCache() {
+	articleVectors.invalidate()
+	chunkVectors.invalidate()
+}
+
+// embedQuery 计算搜索词的向量，结果会被缓存
+func embedQuery(ctx context.Context, query string) (models.Vector, error) {
+	key := embedder.modelName() + "\x00" + query
+	queryEmbeddingMu.Lock()
+	vector, ok := queryEmbeddings[key]
+	queryEmbeddingMu.Unlock()
+	if ok {
+		return vector, nil
+	}
+
+	vectors, err := embedTexts(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	queryEmbeddingMu.Lock()
+	if len(queryEmbeddings) >= queryEmbeddingCacheSize {
+		queryEmbeddings = make(map[string]models.Vector)
+	}
+	queryEmbeddings[key] = vectors[0]
+	queryEmbeddingMu.Unlock()
+	return vectors[0], nil
+}
+
+// SemanticSearchArticles 按与搜索词的向量相似度搜索已发布文章
+// hybrid 为 true 时与关键词搜索的结果按排名融合（RRF），两者都靠前的文章排在最前；language 不为空时只搜索该语言的文章
+func SemanticSearchArticles(ctx context.Context, keyword, language string, hybrid bool, page, pageSize int) (*ArticleListResponse, error) {
+	if !EmbeddingEnabled() {
+		return nil, ErrEmbeddingDisabled
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	resp := &ArticleListResponse{Page: page, PageSize: pageSize, List: []models.Article{}}
+	// 截断过长的搜索词，避免计算向量时消耗过多用量
+	keyword = truncateString(strings.TrimSpace(keyword), SemanticKeywordMaxLength)
+	if keyword == "" {
+		return resp, nil
+	}
+
+	ranked, err := semanticRanking(ctx, keyword, language)
+	if err != nil {
+		return nil, err
+	}
+	if hybrid {
+		var keywordIDs []uint
+		if err := keywordSearchQuery(keyword, language).
+			Order("created_at DESC").
+			Limit(semanticResultLimit).
+			Pluck("id", &keywordIDs).Error; err != nil {
+			return nil, err
+		}
+		ranked = fuseRankings(ranked, keywordIDs)
+	}
+
+	resp.Total = int64(len(ranked))
+	start := (page - 1) * pageSize
+	if start >= len(ranked) {
+		return resp, nil
+	}
+	end := start + pageSize
+	if end > len(ranked) {
+		end = len(ranked)
+	}
+	ids := ranked[start:end]
+
+	var articles []models.Article
+	if err := database.DB.Preload("Author").Preload("Categories").Preload("Tags").
+		Where("id IN ?", ids).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			resp.List = append(resp.List, article)
+		}
+	}
+	AttachArticleReactions(resp.List)
+
+	return resp, nil
+}
+
+// semanticRanking 按相似度排序的已发布文章ID，低于最低相似度的文章不返回
+func semanticRanking(ctx context.Context, keyword, language string) ([]uint, error) {
+	query, err := embedQuery(ctx, keyword)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := articleVectors.get()
+	if err != nil {
+		return nil, err
+	}
+	published, err := publishedArticleIDs(language)
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		id    uint
+		score float64
+	}
+	var results []scored
+	for _, v := range vectors {
+		if !published[v.ArticleID] {
+			continue
+		}
+		if score := dotProduct(query, v.Vector); score > 0 && score >= embeddingMinScore {
+			results = append(results, scored{id: v.ID, score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	if len(results) > semanticResultLimit {
+		results = results[:semanticResultLimit]
+	}
+
+	ids := make([]uint, len(results))
+	for i, r := range results {
+		ids[i] = r.id
+	}
+	return ids, nil
+}
+
+// publishedArticleIDs 已发布文章的ID，language 不为空时只包含该语言的文章
+func publishedArticleIDs(language string) (map[uint]bool, error) {
+	db := database.DB.Model(&models.Article{}).Where("status = ?", "published")
+	if language != "" {
+		db = db.Where("language = ?", strings.ToLower(language))
+	}
+	var ids []uint
+	if err := db.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	published := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		published[id] = true
+	}
+	return published, nil
+}
+
+// fuseRankings 按排名融合多个排序结果：得分为各结果中 1/(k+名次) 之和
+func fuseRankings(rankings ...[]uint) []uint {
+	scores := make(map[uint]float64)
+	var ids []uint
+	for _, ranking := range rankings {
+		for rank, id := range ranking {
+			if _, ok := scores[id]; !ok {
+				ids = append(ids, id)
+			}
+			scores[id] += 1 / float64(hybridRankConstant+rank+1)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return scores[ids[i]] > scores[ids[j]] })
+	if len(ids) > semanticResultLimit {
+		ids = ids[:semanticResultLimit]
+	}
+	return ids
+}
+
+// logEmbeddingError 记录后台计算向量的错误
+func logEmbeddingError(articleID uint, err error) {
+	if err != nil && !errors.Is(err, ErrEmbeddingDisabled) {
+		log.Printf("计算文章 %d 的向量失败: %v", articleID, err)
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+)
+
+// embeddingProvider 向量模型提供方
+type embeddingProvider interface {
+	// embed 批量计算文本的向量，返回顺序与输入一致
+	embed(ctx context.Context, texts []string) ([][]float32, TokenUsage, error)
+	// modelName 模型名称，保存在向量记录中，切换模型后旧向量不再使用
+	modelName() string
+}
+
+// newEmbeddingProvider 根据配置创建向量模型提供方，未配置时返回 nil
+// 未填写 api_key 和 api_url 时使用同名聊天提供方的配置
+func newEmbeddingProvider(cfg config.AIConfig, idleTimeout time.Duration) (embeddingProvider, error) {
+	ec := cfg.Embedding
+	switch strings.ToLower(ec.Provider) {
+	case "":
+		return nil, nil
+	case "qwen":
+		return newOpenAIEmbedder(firstNonEmpty(ec.APIKey, cfg.Qwen.APIKey), firstNonEmpty(ec.APIURL, cfg.Qwen.APIURL), ec.Model, ec.Dimensions, idleTimeout), nil
+	case "openai":
+		return newOpenAIEmbedder(firstNonEmpty(ec.APIKey, cfg.OpenAI.APIKey), firstNonEmpty(ec.APIURL, cfg.OpenAI.APIURL), ec.Model, ec.Dimensions, idleTimeout), nil
+	case "ollama":
+		return newOllamaEmbedder(firstNonEmpty(ec.APIURL, cfg.Ollama.APIURL), ec.Model, idleTimeout), nil
+	case "fake":
+		return newFakeEmbedder(ec.Dimensions), nil
+	default:
+		return nil, fmt.Errorf("不支持的向量模型提供方: %s", ec.Provider)
+	}
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// openAIEmbedder OpenAI 兼容的 /embeddings 接口（通义千问兼容模式、OpenAI 等）
+type openAIEmbedder struct {
+	apiKey      string
+	apiURL      string
+	model       string
+	dimensions  int
+	idleTimeout time.Duration
+	httpClient  *http.Client
+}
+
+// newOpenAIEmbedder 创建 OpenAI 兼容接口的向量模型提供方，apiURL 也兼容填写聊天接口的完整地址
+func newOpenAIEmbedder(apiKey, apiURL, model string, dimensions int, idleTimeout time.Duration) *openAIEmbedder {
+	apiURL = strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/chat/completions")
+	return &openAIEmbedder{
+		apiKey:      apiKey,
+		apiURL:      strings.TrimSuffix(apiURL, "/embeddings"),
+		model:       model,
+		dimensions:  dimensions,
+		idleTimeout: idleTimeout,
+		httpClient:  newAIHTTPClient(idleTimeout),
+	}
+}
+
+// embeddingRequest /embeddings 请求结构
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+// embeddingResponse /embeddings 响应结构
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// modelName 模型名称
+func (s *openAIEmbedder) modelName() string {
+	return s.model
+}
+
+// embed 调用 /embeddings 接口
+func (s *openAIEmbedder) embed(ctx context.Context, texts []string) ([][]float32, TokenUsage, error) {
+	var resp embeddingResponse
+	err := postEmbeddingRequest(ctx, s.httpClient, s.idleTimeout, s.apiURL+"/embeddings", s.apiKey, embeddingRequest{
+		Model:      s.model,
+		Input:      texts,
+		Dimensions: s.dimensions,
+	}, &resp)
+	if err != nil {
+		return nil, TokenUsage{}, err
+	}
+	if resp.Error != nil {
+		return nil, TokenUsage{}, fmt.Errorf("API返回错误: %s", resp.Error.Message)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, TokenUsage{}, fmt.Errorf("API返回的向量数量不符: %d / %d", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, TokenUsage{}, fmt.Errorf("API返回的向量序号错误: %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, TokenUsage{PromptTokens: resp.Usage.PromptTokens}, nil
+}
+
+// ollamaEmbedder Ollama 的 /api/embed 接口
+type ollamaEmbedder struct {
+	apiURL      string
+	model       string
+	idleTimeout time.Duration
+	httpClient  *http.Client
+}
+
+// newOllamaEmbedder 创建本地向量模型提供方
+func newOllamaEmbedder(apiURL, model string, idleTimeout time.Duration) *ollamaEmbedder {
+	apiURL = strings.TrimRight(apiURL, "/")
+	if apiURL == "" {
+		apiURL = "http://localhost:11434"
+	}
+	return &ollamaEmbedder{
+		apiURL:      apiURL,
+		model:       model,
+		idleTimeout: idleTimeout,
+		httpClient:  newAIHTTPClient(idleTimeout),
+	}
+}
+
+// ollamaEmbedRequest /api/embed 请求结构
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaEmbedResponse /api/embed 响应结构
+type ollamaEmbedResponse struct {
+	Embeddings      [][]float32 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	Error           string      `json:"error"`
+}
+
+// modelName 模型名称
+func (s *ollamaEmbedder) modelName() string {
+	return s.model
+}
+
+// embed 调用 /api/embed 接口
+func (s *ollamaEmbedder) embed(ctx context.Context, texts []string) ([][]float32, TokenUsage, error) {
+	var resp ollamaEmbedResponse
+	err := postEmbeddingRequest(ctx, s.httpClient, s.idleTimeout, s.apiURL+"/api/embed", "", ollamaEmbedRequest{
+		Model: s.model,
+		Input: texts,
+	}, &resp)
+	if err != nil {
+		return nil, TokenUsage{}, err
+	}
+	if resp.Error != "" {
+		return nil, TokenUsage{}, fmt.Errorf("API返回错误: %s", resp.Error)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, TokenUsage{}, fmt.Errorf("API返回的向量数量不符: %d / %d", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, TokenUsage{PromptTokens: resp.PromptEvalCount}, nil
+}
+
+// postEmbeddingRequest 发送 JSON 请求并解析响应，非 200 响应返回错误
+func postEmbeddingRequest(ctx context.Context, client *http.Client, idleTimeout time.Duration, url, apiKey string, payload, out interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := doAIRequest(ctx, client, httpReq, idleTimeout)
+	if err != nil {
+		return fmt.Errorf("API请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API返回错误: %s, 响应: %s", resp.Status, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 响应: %s", err, string(body))
+	}
+	return nil
+}
+
+// fakeEmbedder 离线测试用的向量模型
+// 将分词结果哈希到固定维度（特征哈希），词语重合越多的文本相似度越高，结果稳定可复现
+type fakeEmbedder struct {
+	dimensions int
+}
+
+// newFakeEmbedder 创建离线测试用的向量模型
+func newFakeEmbedder(dimensions int) *fakeEmbedder {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &fakeEmbedder{dimensions: dimensions}
+}
+
+// modelName 模型名称，包含维度，修改维度后旧向量不再使用
+func (s *fakeEmbedder) modelName() string {
+	return fmt.Sprintf("fake-%d", s.dimensions)
+}
+
+// embed 计算特征哈希向量，用量按字符数估算
+func (s *fakeEmbedder) embed(ctx context.Context, texts []string) ([][]float32, TokenUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, TokenUsage{}, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	var usage TokenUsage
+	for i, text := range texts {
+		termFreq := make(map[string]float64)
+		for _, term := range tokenize(text) {
+			termFreq[term]++
+		}
+
+		vector := make([]float32, s.dimensions)
+		for term, freq := range termFreq {
+			h := fnv.New64a()
+			h.Write([]byte(term))
+			sum := h.Sum64()
+			weight := float32(1 + math.Log(freq))
+			// 最高位决定符号，减少哈希冲突带来的偏差
+			if sum>>63 == 1 {
+				weight = -weight
+			}
+			vector[sum%uint64(s.dimensions)] += weight
+		}
+		vectors[i] = vector
+		usage.PromptTokens += estimateTokens(text)
+	}
+	return vectors, usage, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/testutil"
+)
+
+func TestNormalizeVector(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []float32
+		want models.Vector
+	}{
+		{"单位化", []float32{3, 4}, models.Vector{0.6, 0.8}},
+		{"保留符号", []float32{0, -2}, models.Vector{0, -1}},
+		{"零向量不变", []float32{0, 0, 0}, models.Vector{0, 0, 0}},
+		{"空向量", []float32{}, models.Vector{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeVector(tt.in)
+			if len(got) != len(tt.want) {
+				t.Fatalf("维度 %d，期望 %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-6 {
+					t.Errorf("normalizeVector(%v) = %v，期望 %v", tt.in, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFuseRankings(t *testing.T) {
+	tests := []struct {
+		name     string
+		rankings [][]uint
+		want     []uint
+	}{
+		{"单个排序保持原顺序", [][]uint{{3, 1, 2}}, []uint{3, 1, 2}},
+		{"两边都出现的排在前面", [][]uint{{1, 2, 3}, {3, 4}}, []uint{3, 1, 2, 4}},
+		{"同分时按首次出现的顺序", [][]uint{{1, 2}, {2, 1}}, []uint{1, 2}},
+		{"只有一边有结果", [][]uint{nil, {5, 6}}, []uint{5, 6}},
+		{"都为空", [][]uint{nil, nil}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuseRankings(tt.rankings...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuseRankings(%v) = %v，期望 %v", tt.rankings, got, tt.want)
+			}
+		})
+	}
+
+	long := make([]uint, semanticResultLimit+10)
+	for i := range long {
+		long[i] = uint(i + 1)
+	}
+	if got := fuseRankings(long); len(got) != semanticResultLimit {
+		t.Errorf("结果数应不超过 %d，实际 %d", semanticResultLimit, len(got))
+	}
+}
+
+func TestChunkArticle(t *testing.T) {
+	long := strings.Repeat("字", askChunkLength+100)
+	tests := []struct {
+		name    string
+		content string
+		want    []articleChunk
+	}{
+		{
+			name:    "标题前的内容没有章节",
+			content: "引言。\n\n## 安装\n\n第一步。\n\n第二步。",
+			want: []articleChunk{
+				{Content: "引言。"},
+				{Heading: "安装", Anchor: "安装", Content: "第一步。\n第二步。"},
+			},
+		},
+		{
+			name:    "跳过代码块和代码中的标题",
+			content: "## 用法\n\n说明。\n\n```go\n# 不是标题\nfmt.Println()\n```\n\n结尾。",
+			want: []articleChunk{
+				{Heading: "用法", Anchor: "用法", Content: "说明。\n结尾。"},
+			},
+		},
+		{
+			name:    "重复标题的锚点与目录一致",
+			content: "## 示例\n\n甲。\n\n## 示例\n\n乙。",
+			want: []articleChunk{
+				{Heading: "示例", Anchor: "示例", Content: "甲。"},
+				{Heading: "示例", Anchor: "示例-1", Content: "乙。"},
+			},
+		},
+		{
+			name:    "超长段落拆分",
+			content: long,
+			want: []articleChunk{
+				{Content: long[:len(strings.Repeat("字", askChunkLength))]},
+				{Content: strings.Repeat("字", 100)},
+			},
+		},
+		{
+			name:    "空内容",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkArticle(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkArticle() =\n%+v\n期望\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVectorCacheDiscardsStaleLoad(t *testing.T) {
+	var cache *vectorCache
+	loads := 0
+	cache = &vectorCache{load: func() ([]cachedVector, error) {
+		loads++
+		if loads == 1 {
+			// 模拟加载期间数据发生变化
+			cache.invalidate()
+			return []cachedVector{{ID: 1}}, nil
+		}
+		return []cachedVector{{ID: 2}}, nil
+	}}
+
+	if items, err := cache.get(); err != nil || len(items) != 1 || items[0].ID != 1 {
+		t.Fatalf("第一次读取应返回本次加载的结果: %v %v", items, err)
+	}
+	items, err := cache.get()
+	if err != nil || len(items) != 1 || items[0].ID != 2 {
+		t.Fatalf("加载期间失效的结果不应写入缓存: %v %v", items, err)
+	}
+	if _, err := cache.get(); err != nil || loads != 2 {
+		t.Errorf("重新加载后应使用缓存，实际加载 %d 次", loads)
+	}
+}
+
+// setupFakeEmbedding 使用离线向量模型，测试结束后恢复
+func setupFakeEmbedding(t *testing.T) {
+	t.Helper()
+	testutil.SetupDB(t)
+	previous := embedder
+	if err := InitEmbedding(config.AIConfig{Embedding: config.EmbeddingConfig{Provider: "fake"}}); err != nil {
+		t.Fatalf("初始化向量模型失败: %v", err)
+	}
+	t.Cleanup(func() {
+		embedder = previous
+		InvalidateEmbeddingCache()
+	})
+}
+
+func TestSemanticRanking(t *testing.T) {
+	setupFakeEmbedding(t)
+	author := testutil.CreateUser(t, "author")
+
+	create := func(title, content, status, language string) uint {
+		t.Helper()
+		article := models.Article{Title: title, Content: content, Status: status, Language: language, AuthorID: author.ID}
+		if err := database.DB.Create(&article).Error; err != nil {
+			t.Fatalf("创建文章失败: %v", err)
+		}
+		if err := IndexArticleEmbedding(context.Background(), article.ID); err != nil {
+			t.Fatalf("计算向量失败: %v", err)
+		}
+		return article.ID
+	}
+	goroutines := create("goroutine channel", "goroutine channel select goroutine", "published", "en")
+	mysql := create("database index", "mysql index query plan", "published", "en")
+	draft := create("goroutine draft", "goroutine channel select goroutine", "draft", "en")
+	chinese := create("goroutine zh", "goroutine channel", "published", "zh")
+
+	tests := []struct {
+		name     string
+		keyword  string
+		language string
+		first    uint
+		excluded []uint
+	}{
+		{"最相似的排在最前", "goroutine channel", "", goroutines, []uint{draft}},
+		{"按语言过滤", "goroutine channel", "en", goroutines, []uint{draft, chinese}},
+		{"其他主题", "mysql index", "en", mysql, []uint{draft}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := semanticRanking(context.Background(), tt.keyword, tt.language)
+			if err != nil {
+				t.Fatalf("语义排序失败: %v", err)
+			}
+			if len(ids) == 0 || ids[0] != tt.first {
+				t.Fatalf("第一个结果应为 %d，实际 %v", tt.first, ids)
+			}
+			for _, id := range ids {
+				for _, excluded := range tt.excluded {
+					if id == excluded {
+						t.Errorf("结果不应包含 %d: %v", excluded, ids)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+
+	"go-blog/internal/events"
+)
+
+// RegisterEventHandlers 注册领域事件的订阅者
+func RegisterEventHandlers() {
+	// 相关文章缓存：同步失效，保证后续请求读取到最新数据
+	invalidateRelated := func(events.Event) { InvalidateRelatedCache() }
+	events.Subscribe(events.ArticleCreated, invalidateRelated)
+	events.Subscribe(events.ArticleUpdated, invalidateRelated)
+	events.Subscribe(events.ArticleDeleted, invalidateRelated)
+
+	// AI摘要：发布时或已发布文章的摘要重新截取时生成
+	aiSummary := func(e events.Event) {
+		payload := e.Payload.(events.ArticlePayload)
+		article := payload.Article
+		if article.SummaryAuto && article.Status == "published" && (payload.Published || payload.SummaryRegenerated) {
+			generateAISummary(article.ID)
+		}
+	}
+	events.SubscribeAsync(events.ArticleCreated, aiSummary)
+	events.SubscribeAsync(events.ArticleUpdated, aiSummary)
+
+	// 语义搜索：文章创建或修改后更新向量，文本未变化时跳过，下线时删除
+	updateEmbedding := func(e events.Event) {
+		if !EmbeddingEnabled() {
+			return
+		}
+		article := e.Payload.(events.ArticlePayload).Article
+		logEmbeddingError(article.ID, IndexArticleEmbedding(context.Background(), article.ID))
+	}
+	events.SubscribeAsync(events.ArticleCreated, updateEmbedding)
+	events.SubscribeAsync(events.ArticleUpdated, updateEmbedding)
+
+	// 订阅邮件：推送新发布的文章
+	events.SubscribeAsync(events.ArticlePublished, func(e events.Event) {
+		NotifySubscribers(e.Payload.(events.ArticlePayload).Article.ID)
+	})
+
+	// 评论通知：邮件通知作者和被回复者
+	events.SubscribeAsync(events.CommentCreated, func(e events.Event) {
+		comment := e.Payload.(events.CommentPayload).Comment
+		NotifyNewComment(&comment)
+	})
+
+	// Webhook：草稿的修改和删除不对外推送，已发布文章下线时仍推送一次
+	events.SubscribeAsync(events.ArticlePublished, func(e events.Event) {
+		article := e.Payload.(events.ArticlePayload).Article
+		TriggerWebhook(WebhookEventArticlePublished, webhookArticleData(&article))
+	})
+	events.SubscribeAsync(events.ArticleUpdated, func(e events.Event) {
+		payload := e.Payload.(events.ArticlePayload)
+		if !payload.Published && (payload.Article.Status == "published" || payload.WasPublished) {
+			TriggerWebhook(WebhookEventArticleUpdated, webhookArticleData(&payload.Article))
+		}
+	})
+	events.SubscribeAsync(events.ArticleDeleted, func(e events.Event) {
+		payload := e.Payload.(events.ArticlePayload)
+		if payload.WasPublished {
+			TriggerWebhook(WebhookEventArticleDeleted, webhookArticleData(&payload.Article))
+		}
+	})
+	events.SubscribeAsync(events.CommentCreated, func(e events.Event) {
+		comment := e.Payload.(events.CommentPayload).Comment
+		TriggerWebhook(WebhookEventCommentCreated, webhookCommentData(&comment))
+	})
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"go-blog/internal/config"
+)
+
+// fakeProvider 离线测试用的模型提供方，相同的提示词总是返回相同的内容
+type fakeProvider struct {
+	response  string
+	chunkSize int
+}
+
+// newFakeProvider 创建离线测试用的提供方
+func newFakeProvider(cfg config.FakeAIConfig) *fakeProvider {
+	chunkSize := cfg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 8
+	}
+	return &fakeProvider{response: cfg.Response, chunkSize: chunkSize}
+}
+
+// complete 返回固定回复；未配置时根据提示词生成确定的内容，用量按字符数估算
+// 提示词最后一行为 JSON 示例时（如推荐标题和标签）原样返回该示例，便于离线测试需要解析结构化结果的接口
+func (s *fakeProvider) complete(ctx context.Context, prompt string) (string, TokenUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return "", TokenUsage{}, err
+	}
+
+	content := s.response
+	if content == "" {
+		content = jsonExample(prompt)
+	}
+	if content == "" {
+		firstLine := strings.TrimSpace(strings.SplitN(strings.TrimSpace(prompt), "\n", 2)[0])
+		content = fmt.Sprintf("这是模拟生成的内容（%08x）：%s", crc32.ChecksumIEEE([]byte(prompt)), truncateString(firstLine, 50))
+	}
+	return content, estimateTokenUsage(prompt, content), nil
+}
+
+// stream 将回复按固定字符数分段返回
+func (s *fakeProvider) stream(ctx context.Context, prompt string, callback func(string) error) (TokenUsage, error) {
+	content, usage, err := s.complete(ctx, prompt)
+	if err != nil {
+		return TokenUsage{}, err
+	}
+
+	runes := []rune(content)
+	for start := 0; start < len(runes); start += s.chunkSize {
+		if err := ctx.Err(); err != nil {
+			return TokenUsage{}, err
+		}
+		end := start + s.chunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		if err := callback(string(runes[start:end])); err != nil {
+			return TokenUsage{}, err
+		}
+	}
+	return usage, nil
+}
+
+// jsonExample 提示词最后一行的 JSON 示例，没有时返回空字符串
+func jsonExample(prompt string) string {
+	lines := strings.Split(strings.TrimSpace(prompt), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if strings.HasPrefix(last, "{") && json.Valid([]byte(last)) {
+		return last
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/xml"
+	"sort"
+	"time"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// feedSize RSS 中的文章数
+const feedSize = 20
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type sitemapURLSet struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsXhtml string       `xml:"xmlns:xhtml,attr"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"` // 其他语言版本（hreflang），包括自身
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// BuildRSS 生成某一语言最近发布文章的 RSS 2.0 订阅，language 为空时使用站点默认语言
+func BuildRSS(language string) ([]byte, error) {
+	settings, err := GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	if language == "" {
+		language = siteLanguage(settings)
+	} else if language, err = NormalizeLanguage(language); err != nil {
+		return nil, err
+	}
+
+	var articles []models.Article
+	if err := database.DB.Preload("Categories").
+		Where("status = ? AND language = ?", "published", language).
+		Order("published_at DESC, created_at DESC").
+		Limit(feedSize).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return renderRSS(articles, settings, language)
+}
+
+// BuildSitemap 生成包含各语言首页和全部已发布文章的站点地图
+func BuildSitemap() ([]byte, error) {
+	settings, err := GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []models.Article
+	if err := database.DB.Select("id", "language", "translation_of_id", "updated_at").
+		Where("status = ?", "published").
+		Order("id ASC").
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return renderSitemap(sitemapEntries(articles, languageHomePaths(articles, siteLanguage(settings))))
+}
+
+// renderRSS 使用该语言的站点设置渲染 RSS
+func renderRSS(articles []models.Article, settings map[string]string, language string) ([]byte, error) {
+	siteLang := siteLanguage(settings)
+	settings = LocalizeSettings(settings, language)
+	title := settings["site_name"]
+	if title == "" {
+		title = siteName()
+	}
+
+	channel := rssChannel{
+		Title:       title,
+		Link:        siteURL() + languageHomePath(language, siteLang),
+		Description: settings["site_description"],
+		Language:    language,
+		Items:       make([]rssItem, 0, len(articles)),
+	}
+	if len(articles) > 0 {
+		channel.LastBuildDate = articleDate(&articles[0]).Format(time.RFC1123Z)
+	}
+
+	for _, article := range articles {
+		link := articleURL(article.ID)
+		item := rssItem{
+			Title:       article.Title,
+			Link:        link,
+			GUID:        link,
+			Description: article.Summary,
+			PubDate:     articleDate(&article).Format(time.RFC1123Z),
+		}
+		for _, category := range article.Categories {
+			item.Categories = append(item.Categories, category.Name)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	data, err := xml.MarshalIndent(rssFeed{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// sitemapEntries 生成首页、文章和额外页面的地址，有译文的文章附带各语言版本的 hreflang 链接
+func sitemapEntries(articles []models.Article, extraPaths []string) []sitemapURL {
+	groups := make(map[uint][]sitemapAlternate)
+	for _, article := range articles {
+		groupID := translationGroupID(&article)
+		groups[groupID] = append(groups[groupID], sitemapAlternate{
+			Rel:      "alternate",
+			Hreflang: article.Language,
+			Href:     articleURL(article.ID),
+		})
+	}
+
+	entries := []sitemapURL{{Loc: siteURL() + "/"}}
+	for _, article := range articles {
+		entry := sitemapURL{
+			Loc:     articleURL(article.ID),
+			LastMod: article.UpdatedAt.Format("2006-01-02"),
+		}
+		if group := groups[translationGroupID(&article)]; len(group) > 1 {
+			entry.Alternates = group
+		}
+		entries = append(entries, entry)
+	}
+	for _, path := range extraPaths {
+		entries = append(entries, sitemapURL{Loc: siteURL() + path})
+	}
+	return entries
+}
+
+// languageHomePaths 默认语言以外、有已发布文章的各语言首页
+func languageHomePaths(articles []models.Article, siteLang string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, article := range articles {
+		if article.Language == siteLang || seen[article.Language] {
+			continue
+		}
+		seen[article.Language] = true
+		paths = append(paths, languageHomePath(article.Language, siteLang))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// renderSitemap 渲染站点地图
+func renderSitemap(entries []sitemapURL) ([]byte, error) {
+	data, err := xml.MarshalIndent(sitemapURLSet{
+		Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XmlnsXhtml: "http://www.w3.org/1999/xhtml",
+		URLs:       entries,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// articleDate 文章的发布时间，旧数据没有发布时间时使用创建时间
+func articleDate(article *models.Article) time.Time {
+	if article.PublishedAt != nil {
+		return *article.PublishedAt
+	}
+	return article.CreatedAt
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// geoIPRange IP段与国家代码
+type geoIPRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// GeoIPDB 本地 GeoIP 数据库
+// 数据文件为CSV格式，每行：起始IP,结束IP,国家代码（兼容 DB-IP / IP2Location Lite 等导出格式）
+type GeoIPDB struct {
+	ranges []geoIPRange
+}
+
+// LoadGeoIPDB 加载本地 GeoIP CSV 文件
+func LoadGeoIPDB(path string) (*GeoIPDB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开GeoIP文件失败: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &GeoIPDB{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析GeoIP文件失败: %w", err)
+		}
+		if len(record) < 3 {
+			continue
+		}
+
+		// 跳过表头和无法解析的行
+		start, err1 := netip.ParseAddr(strings.TrimSpace(record[0]))
+		end, err2 := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err1 != nil || err2 != nil || start.Is4() != end.Is4() {
+			continue
+		}
+
+		db.ranges = append(db.ranges, geoIPRange{
+			start:   start.Unmap(),
+			end:     end.Unmap(),
+			country: strings.ToUpper(strings.TrimSpace(record[2])),
+		})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+
+	return db, nil
+}
+
+// Lookup 查询IP所属国家代码，未找到时返回空字符串
+func (db *GeoIPDB) Lookup(ip string) string {
+	if db == nil || len(db.ranges) == 0 {
+		return ""
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// 找到最后一个起始IP不大于目标IP的区间
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return ""
+	}
+
+	r := db.ranges[i]
+	if r.start.Is4() != addr.Is4() || r.end.Less(addr) {
+		return ""
+	}
+	return r.country
+}
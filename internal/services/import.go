@@ -0,0 +1,372 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 导入格式
+const (
+	ImportFormatWXR      = "wxr"      // WordPress 导出文件
+	ImportFormatMarkdown = "markdown" // Hexo/Hugo 等带 Front Matter 的 Markdown 文件
+)
+
+// ImportData 待导入的内容，由各格式解析得到
+type ImportData struct {
+	Categories []ImportCategory
+	Articles   []ImportArticle
+}
+
+// ImportCategory 待导入的分类
+type ImportCategory struct {
+	Name        string
+	Description string
+}
+
+// ImportArticle 待导入的文章，正文为Markdown
+type ImportArticle struct {
+	Source      string // 来源（文件名或原文链接），用于报告
+	Title       string
+	Content     string
+	Summary     string
+	Status      string // draft, published
+	Language    string // 语言代码，为空或无效时使用站点默认语言
+	Categories  []string
+	Tags        []string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PublishedAt *time.Time
+	Comments    []ImportComment
+}
+
+// ImportComment 待导入的评论
+type ImportComment struct {
+	SourceID       string // 原评论ID，用于关联回复
+	ParentSourceID string
+	Nickname       string
+	Email          string
+	Content        string
+	CreatedAt      time.Time
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	AuthorID uint
+	DryRun   bool // 仅统计，不写入数据库
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	DryRun            bool     `json:"dry_run"`
+	Articles          int      `json:"articles"`           // 导入的文章数
+	Published         int      `json:"published"`          // 其中已发布的文章数
+	Drafts            int      `json:"drafts"`             // 其中草稿数
+	Comments          int      `json:"comments"`           // 导入的评论数
+	CreatedCategories []string `json:"created_categories"` // 新建的分类
+	CreatedTags       []string `json:"created_tags"`       // 新建的标签
+	Skipped           []string `json:"skipped"`            // 已存在而跳过的文章
+	Warnings          []string `json:"warnings"`
+}
+
+// Import 导入内容，标题已存在的文章会被跳过
+// 导入不触发领域事件，避免向订阅者和 Webhook 批量推送历史文章
+func Import(data *ImportData, opts ImportOptions) (*ImportReport, error) {
+	var author models.User
+	if err := database.DB.First(&author, opts.AuthorID).Error; err != nil {
+		return nil, errors.New("作者不存在")
+	}
+
+	report := &ImportReport{
+		DryRun:            opts.DryRun,
+		CreatedCategories: []string{},
+		CreatedTags:       []string{},
+		Skipped:           []string{},
+		Warnings:          []string{},
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		im := &importer{
+			tx:         tx,
+			opts:       opts,
+			report:     report,
+			categories: make(map[string]*models.Category),
+			tags:       make(map[string]*models.Tag),
+		}
+
+		for _, category := range data.Categories {
+			if _, err := im.category(category.Name, category.Description); err != nil {
+				return err
+			}
+		}
+
+		for i := range data.Articles {
+			if err := im.article(&data.Articles[i]); err != nil {
+				return err
+			}
+		}
+
+		if opts.DryRun {
+			// 预览模式回滚全部写入
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		InvalidateRelatedCache()
+	}
+	return report, nil
+}
+
+var errDryRun = errors.New("dry run")
+
+// importer 单次导入的状态
+type importer struct {
+	tx         *gorm.DB
+	opts       ImportOptions
+	report     *ImportReport
+	categories map[string]*models.Category
+	tags       map[string]*models.Tag
+}
+
+// article 导入单篇文章及其评论
+func (im *importer) article(item *ImportArticle) error {
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		im.report.Warnings = append(im.report.Warnings, fmt.Sprintf("%s: 缺少标题，已跳过", item.Source))
+		return nil
+	}
+	if len([]rune(title)) > 255 {
+		title = truncateString(title, 255)
+	}
+
+	var count int64
+	im.tx.Model(&models.Article{}).Where("title = ?", title).Count(&count)
+	if count > 0 {
+		im.report.Skipped = append(im.report.Skipped, title)
+		return nil
+	}
+
+	status := item.Status
+	if status != "published" {
+		status = "draft"
+	}
+	createdAt := item.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	updatedAt := item.UpdatedAt
+	if updatedAt.Before(createdAt) {
+		updatedAt = createdAt
+	}
+
+	language, err := articleLanguage(item.Language)
+	if err != nil {
+		im.report.Warnings = append(im.report.Warnings, fmt.Sprintf("%s: %v，已使用默认语言", item.Source, err))
+		language, _ = articleLanguage("")
+	}
+
+	meta := analyzeContent(item.Content)
+	article := models.Article{
+		Title:       title,
+		Content:     item.Content,
+		Summary:     truncateString(strings.TrimSpace(item.Summary), maxSummaryLength),
+		AuthorID:    im.opts.AuthorID,
+		Status:      status,
+		Language:    language,
+		TOC:         meta.TOC,
+		WordCount:   meta.WordCount,
+		ReadingTime: meta.ReadingTime,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	}
+	if article.Summary == "" {
+		article.Summary = GenerateSummary(article.Content, summaryMaxLength())
+		article.SummaryAuto = true
+	}
+	if status == "published" {
+		publishedAt := createdAt
+		if item.PublishedAt != nil {
+			publishedAt = *item.PublishedAt
+		}
+		article.PublishedAt = &publishedAt
+		// 历史文章不再推送给订阅者
+		article.NewsletterSentAt = &publishedAt
+	}
+
+	if err := im.tx.Create(&article).Error; err != nil {
+		return fmt.Errorf("导入文章《%s》失败: %w", title, err)
+	}
+
+	var categories []models.Category
+	for _, name := range item.Categories {
+		category, err := im.category(name, "")
+		if err != nil {
+			return err
+		}
+		if category != nil {
+			categories = append(categories, *category)
+		}
+	}
+	if len(categories) > 0 {
+		if err := im.tx.Model(&article).Association("Categories").Replace(categories); err != nil {
+			return err
+		}
+	}
+
+	var tags []models.Tag
+	for _, name := range item.Tags {
+		tag, err := im.tag(name)
+		if err != nil {
+			return err
+		}
+		if tag != nil {
+			tags = append(tags, *tag)
+		}
+	}
+	if len(tags) > 0 {
+		if err := im.tx.Model(&article).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+	}
+
+	if err := im.comments(article.ID, item.Comments); err != nil {
+		return err
+	}
+
+	im.report.Articles++
+	if status == "published" {
+		im.report.Published++
+	} else {
+		im.report.Drafts++
+	}
+	return nil
+}
+
+// comments 导入评论，按原ID还原回复关系
+func (im *importer) comments(articleID uint, items []ImportComment) error {
+	// 按时间排序，保证被回复的评论先导入
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	ids := make(map[string]uint, len(items))
+	for _, item := range items {
+		content := strings.TrimSpace(item.Content)
+		if content == "" {
+			continue
+		}
+
+		comment := models.Comment{
+			ArticleID: articleID,
+			Nickname:  truncateString(strings.TrimSpace(item.Nickname), 50),
+			Email:     truncateString(strings.TrimSpace(item.Email), 100),
+			Content:   content,
+			CreatedAt: item.CreatedAt,
+		}
+		if comment.Nickname == "" {
+			comment.Nickname = "匿名"
+		}
+		if parentID, ok := ids[item.ParentSourceID]; ok && item.ParentSourceID != "" {
+			comment.ParentID = &parentID
+		}
+
+		if err := im.tx.Create(&comment).Error; err != nil {
+			return fmt.Errorf("导入评论失败: %w", err)
+		}
+		if item.SourceID != "" {
+			ids[item.SourceID] = comment.ID
+		}
+		im.report.Comments++
+	}
+	return nil
+}
+
+// category 按名称查找分类，不存在时创建
+func (im *importer) category(name, description string) (*models.Category, error) {
+	name = truncateString(strings.TrimSpace(name), 50)
+	if name == "" {
+		return nil, nil
+	}
+	if category, ok := im.categories[name]; ok {
+		return category, nil
+	}
+
+	var category models.Category
+	err := im.tx.Where("name = ?", name).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		category = models.Category{Name: name, Description: truncateString(description, 255)}
+		if err := im.tx.Create(&category).Error; err != nil {
+			return nil, fmt.Errorf("创建分类 %s 失败: %w", name, err)
+		}
+		im.report.CreatedCategories = append(im.report.CreatedCategories, name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	im.categories[name] = &category
+	return &category, nil
+}
+
+// tag 按名称查找标签，不存在时创建
+func (im *importer) tag(name string) (*models.Tag, error) {
+	name = truncateString(strings.TrimSpace(name), 50)
+	if name == "" {
+		return nil, nil
+	}
+	if tag, ok := im.tags[name]; ok {
+		return tag, nil
+	}
+
+	var tag models.Tag
+	err := im.tx.Where("name = ?", name).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tag = models.Tag{Name: name}
+		if err := im.tx.Create(&tag).Error; err != nil {
+			return nil, fmt.Errorf("创建标签 %s 失败: %w", name, err)
+		}
+		im.report.CreatedTags = append(im.report.CreatedTags, name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	im.tags[name] = &tag
+	return &tag, nil
+}
+
+// ParseImportFile 解析导入文件：WXR 为 XML 文件，Markdown 为单个文件或 zip 压缩包
+func ParseImportFile(format, filename string, content []byte) (*ImportData, error) {
+	switch format {
+	case ImportFormatWXR:
+		return ParseWXR(bytes.NewReader(content))
+	case ImportFormatMarkdown:
+		if strings.EqualFold(path.Ext(filename), ".zip") {
+			archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				return nil, fmt.Errorf("读取压缩包失败: %w", err)
+			}
+			return ParseMarkdownFiles(archive)
+		}
+		article, err := parseMarkdownFile(path.Base(filename), content, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &ImportData{Articles: []ImportArticle{*article}}, nil
+	default:
+		return nil, fmt.Errorf("不支持的导入格式: %s", format)
+	}
+}
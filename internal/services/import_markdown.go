@@ -0,0 +1,266 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// Front Matter 中常见的时间格式
+var frontMatterTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// Markdown 文件大小上限，防止压缩包解压后体积过大
+const (
+	maxImportEntrySize = 10 << 20  // 单个文件
+	maxImportTotalSize = 200 << 20 // 所有文件合计
+)
+
+// 摘要分隔标记（Hexo/Hugo 均使用）
+var moreMarkerPattern = regexp.MustCompile(`(?i)<!--\s*more\s*-->`)
+
+// ParseMarkdownFiles 解析目录中带 YAML（---）或 TOML（+++）Front Matter 的 Markdown 文件
+// 兼容 Hexo（published、updated、categories 层级）和 Hugo（draft、lastmod）的常用字段
+func ParseMarkdownFiles(fsys fs.FS) (*ImportData, error) {
+	data := &ImportData{}
+	var total int64
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 跳过隐藏目录，如 .git
+			if name != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+		// Hugo 的 _index.md 是列表页，不是文章
+		if path.Base(name) == "_index.md" {
+			return nil
+		}
+
+		content, err := readImportEntry(fsys, name, maxImportTotalSize-total)
+		if err != nil {
+			return err
+		}
+		total += int64(len(content))
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		article, err := parseMarkdownFile(name, content, info.ModTime())
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		data.Articles = append(data.Articles, *article)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取Markdown文件失败: %w", err)
+	}
+
+	return data, nil
+}
+
+// readImportEntry 读取单个文件，超过单文件上限或剩余的合计额度时返回错误
+// 按实际读取的字节计算，不信任压缩包中记录的文件大小
+func readImportEntry(fsys fs.FS, name string, remaining int64) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	limit := min(int64(maxImportEntrySize), remaining)
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		if limit < maxImportEntrySize {
+			return nil, fmt.Errorf("文件合计超过%dMB", maxImportTotalSize>>20)
+		}
+		return nil, fmt.Errorf("%s: 文件超过%dMB", name, maxImportEntrySize>>20)
+	}
+	return content, nil
+}
+
+// parseMarkdownFile 解析单个 Markdown 文件
+func parseMarkdownFile(name string, content []byte, modTime time.Time) (*ImportArticle, error) {
+	meta, body, err := splitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+
+	article := &ImportArticle{
+		Source: name,
+		Title:  frontMatterString(meta, "title"),
+		Status: "published",
+	}
+	if article.Title == "" {
+		article.Title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+
+	// Hugo 使用 draft: true，Hexo 使用 published: false
+	if draft, ok := meta["draft"].(bool); ok && draft {
+		article.Status = "draft"
+	}
+	if published, ok := meta["published"].(bool); ok && !published {
+		article.Status = "draft"
+	}
+
+	article.CreatedAt = frontMatterTime(meta, "date")
+	if article.CreatedAt.IsZero() {
+		article.CreatedAt = modTime
+	}
+	article.UpdatedAt = frontMatterTime(meta, "updated", "lastmod")
+	if article.UpdatedAt.IsZero() {
+		article.UpdatedAt = article.CreatedAt
+	}
+	if article.Status == "published" {
+		publishedAt := article.CreatedAt
+		if t := frontMatterTime(meta, "publishDate", "publishdate"); !t.IsZero() {
+			publishedAt = t
+		}
+		article.PublishedAt = &publishedAt
+	}
+
+	article.Categories = frontMatterList(meta, "categories", "category")
+	article.Tags = frontMatterList(meta, "tags", "tag")
+	article.Summary = frontMatterString(meta, "summary", "description", "excerpt")
+	article.Language = frontMatterString(meta, "lang", "language")
+
+	// <!-- more --> 之前的内容作为摘要
+	body = strings.TrimSpace(body)
+	if loc := moreMarkerPattern.FindStringIndex(body); loc != nil {
+		if article.Summary == "" {
+			article.Summary = GenerateSummary(body[:loc[0]], maxSummaryLength)
+		}
+		body = strings.TrimSpace(body[:loc[0]]) + "\n\n" + strings.TrimSpace(body[loc[1]:])
+	}
+	article.Content = body
+
+	return article, nil
+}
+
+// splitFrontMatter 拆分 Front Matter 和正文，没有 Front Matter 时返回空元数据
+func splitFrontMatter(content []byte) (map[string]interface{}, string, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	meta := make(map[string]interface{})
+
+	var delimiter string
+	switch {
+	case strings.HasPrefix(text, "---\n"):
+		delimiter = "---"
+	case strings.HasPrefix(text, "+++\n"):
+		delimiter = "+++"
+	default:
+		return meta, text, nil
+	}
+
+	rest := text[len(delimiter)+1:]
+	end := strings.Index(rest, "\n"+delimiter)
+	if end < 0 {
+		return nil, "", fmt.Errorf("Front Matter 未闭合")
+	}
+	header := rest[:end]
+	body := strings.TrimPrefix(rest[end+len(delimiter)+1:], "\n")
+
+	var err error
+	if delimiter == "---" {
+		err = yaml.Unmarshal([]byte(header), &meta)
+	} else {
+		err = toml.Unmarshal([]byte(header), &meta)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("解析 Front Matter 失败: %w", err)
+	}
+	return meta, body, nil
+}
+
+// frontMatterString 读取第一个存在的字符串字段
+func frontMatterString(meta map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := meta[key]; ok && value != nil {
+			if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// frontMatterTime 读取第一个可解析的时间字段
+// YAML 解析为 time.Time，TOML 的本地时间类型通过字符串形式解析
+func frontMatterTime(meta map[string]interface{}, keys ...string) time.Time {
+	for _, key := range keys {
+		value, ok := meta[key]
+		if !ok || value == nil {
+			continue
+		}
+		if t, ok := value.(time.Time); ok {
+			return t
+		}
+		s := strings.TrimSpace(fmt.Sprint(value))
+		for _, layout := range frontMatterTimeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// frontMatterList 读取字符串或列表字段，嵌套列表（Hexo 层级分类）会被展开
+func frontMatterList(meta map[string]interface{}, keys ...string) []string {
+	var result []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		case string:
+			// 兼容逗号分隔的写法
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" && !containsString(result, item) {
+					result = append(result, item)
+				}
+			}
+		default:
+			collect(fmt.Sprint(v))
+		}
+	}
+
+	for _, key := range keys {
+		if value, ok := meta[key]; ok {
+			collect(value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-blog/pkg/utils"
+)
+
+const wxrTimeLayout = "2006-01-02 15:04:05"
+
+var (
+	// WordPress 短代码，如 [caption id="..."]...[/caption]
+	shortcodePattern = regexp.MustCompile(`\[/?(caption|gallery|embed|video|audio)[^\]]*\]`)
+	blockTagPattern  = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|!--)`)
+	paragraphPattern = regexp.MustCompile(`\n\s*\n`)
+	preBlockPattern  = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>`)
+	paragraphTag     = regexp.MustCompile(`(?i)<p[\s>]`)
+)
+
+type wxrRSS struct {
+	Channel wxrChannel `xml:"channel"`
+}
+
+type wxrChannel struct {
+	Categories []wxrCategory `xml:"category"`
+	Items      []wxrItem     `xml:"item"`
+}
+
+type wxrCategory struct {
+	Name        string `xml:"cat_name"`
+	Description string `xml:"category_description"`
+}
+
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	PubDate    string        `xml:"pubDate"`
+	Encoded    []wxrEncoded  `xml:"encoded"`
+	PostDate   string        `xml:"post_date"`
+	PostGMT    string        `xml:"post_date_gmt"`
+	Modified   string        `xml:"post_modified"`
+	ModGMT     string        `xml:"post_modified_gmt"`
+	Status     string        `xml:"status"`
+	PostType   string        `xml:"post_type"`
+	Categories []wxrItemTerm `xml:"category"`
+	Comments   []wxrComment  `xml:"comment"`
+}
+
+// wxrEncoded content:encoded 与 excerpt:encoded 本地名相同，通过命名空间区分
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrItemTerm struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	ID       string `xml:"comment_id"`
+	Author   string `xml:"comment_author"`
+	Email    string `xml:"comment_author_email"`
+	Date     string `xml:"comment_date"`
+	DateGMT  string `xml:"comment_date_gmt"`
+	Content  string `xml:"comment_content"`
+	Approved string `xml:"comment_approved"`
+	Type     string `xml:"comment_type"`
+	Parent   string `xml:"comment_parent"`
+}
+
+// ParseWXR 解析 WordPress 导出文件（WXR），仅导入文章，页面和附件会被忽略
+func ParseWXR(r io.Reader) (*ImportData, error) {
+	var rss wxrRSS
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// WordPress 导出文件均为 UTF-8
+		return input, nil
+	}
+	if err := decoder.Decode(&rss); err != nil {
+		return nil, fmt.Errorf("解析WXR文件失败: %w", err)
+	}
+
+	data := &ImportData{}
+	for _, category := range rss.Channel.Categories {
+		data.Categories = append(data.Categories, ImportCategory{
+			Name:        html.UnescapeString(strings.TrimSpace(category.Name)),
+			Description: strings.TrimSpace(category.Description),
+		})
+	}
+
+	for _, item := range rss.Channel.Items {
+		if item.PostType != "" && item.PostType != "post" {
+			continue
+		}
+		if item.Status == "trash" || item.Status == "auto-draft" || item.Status == "inherit" {
+			continue
+		}
+
+		article, err := item.toArticle()
+		if err != nil {
+			return nil, err
+		}
+		data.Articles = append(data.Articles, *article)
+	}
+
+	return data, nil
+}
+
+// toArticle 转换为待导入文章
+func (item *wxrItem) toArticle() (*ImportArticle, error) {
+	var content, excerpt string
+	for _, encoded := range item.Encoded {
+		if strings.Contains(encoded.XMLName.Space, "excerpt") {
+			excerpt = encoded.Value
+		} else {
+			content = encoded.Value
+		}
+	}
+
+	markdown, err := utils.HTMLToMarkdown(wpAutoP(shortcodePattern.ReplaceAllString(content, "")))
+	if err != nil {
+		return nil, fmt.Errorf("转换文章《%s》失败: %w", item.Title, err)
+	}
+	summary, err := utils.HTMLToMarkdown(excerpt)
+	if err != nil {
+		summary = ""
+	}
+
+	article := &ImportArticle{
+		Source:    item.Link,
+		Title:     html.UnescapeString(strings.TrimSpace(item.Title)),
+		Content:   markdown,
+		Summary:   stripInlineMarkdown(summary),
+		Status:    "draft",
+		CreatedAt: wxrTime(item.PostGMT, item.PostDate),
+		UpdatedAt: wxrTime(item.ModGMT, item.Modified),
+	}
+	if article.CreatedAt.IsZero() {
+		if t, err := time.Parse(time.RFC1123Z, strings.TrimSpace(item.PubDate)); err == nil {
+			article.CreatedAt = t
+		}
+	}
+	if item.Status == "publish" {
+		article.Status = "published"
+		publishedAt := article.CreatedAt
+		article.PublishedAt = &publishedAt
+	}
+
+	for _, term := range item.Categories {
+		name := html.UnescapeString(strings.TrimSpace(term.Name))
+		switch term.Domain {
+		case "category":
+			if name != "Uncategorized" && name != "未分类" {
+				article.Categories = append(article.Categories, name)
+			}
+		case "post_tag":
+			article.Tags = append(article.Tags, name)
+		}
+	}
+
+	for _, comment := range item.Comments {
+		// 仅导入已审核的普通评论，忽略垃圾评论和 pingback/trackback
+		if comment.Approved != "1" || (comment.Type != "" && comment.Type != "comment") {
+			continue
+		}
+		text, err := utils.HTMLToMarkdown(wpAutoP(comment.Content))
+		if err != nil {
+			text = comment.Content
+		}
+		parent := comment.Parent
+		if parent == "0" {
+			parent = ""
+		}
+		article.Comments = append(article.Comments, ImportComment{
+			SourceID:       comment.ID,
+			ParentSourceID: parent,
+			Nickname:       html.UnescapeString(comment.Author),
+			Email:          comment.Email,
+			Content:        text,
+			CreatedAt:      wxrTime(comment.DateGMT, comment.Date),
+		})
+	}
+
+	return article, nil
+}
+
+// wxrTime 解析 WordPress 时间，优先使用 GMT 时间
+func wxrTime(gmt, local string) time.Time {
+	if t, err := time.Parse(wxrTimeLayout, strings.TrimSpace(gmt)); err == nil && t.Year() > 1 {
+		return t
+	}
+	if t, err := time.ParseInLocation(wxrTimeLayout, strings.TrimSpace(local), time.Local); err == nil && t.Year() > 1 {
+		return t
+	}
+	return time.Time{}
+}
+
+// wpAutoP 还原 WordPress 的自动分段：空行分隔段落，段内换行视为换行
+func wpAutoP(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if paragraphTag.MatchString(content) {
+		return content
+	}
+
+	// 代码块中的空行不是段落分隔，先替换为占位符
+	var pres []string
+	content = preBlockPattern.ReplaceAllStringFunc(content, func(pre string) string {
+		pres = append(pres, pre)
+		return fmt.Sprintf("\n\n<pre-placeholder-%d>\n\n", len(pres)-1)
+	})
+
+	var buf strings.Builder
+	for _, block := range paragraphPattern.Split(strings.TrimSpace(content), -1) {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		var index int
+		if _, err := fmt.Sscanf(block, "<pre-placeholder-%d>", &index); err == nil && index < len(pres) {
+			buf.WriteString(pres[index] + "\n")
+			continue
+		}
+		if blockTagPattern.MatchString(block) {
+			buf.WriteString(block + "\n")
+			continue
+		}
+		buf.WriteString("<p>" + strings.ReplaceAll(block, "\n", "<br>\n") + "</p>\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// defaultLanguage 未指定语言时文章的语言
+const defaultLanguage = "zh"
+
+// languageCodePattern 语言代码，如 zh、en、zh-TW、pt-BR
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// languageNames 常用语言的名称，用于提示词
+var languageNames = map[string]string{
+	"zh":    "简体中文",
+	"zh-cn": "简体中文",
+	"zh-tw": "繁体中文",
+	"zh-hk": "繁体中文",
+	"en":    "英文",
+	"ja":    "日文",
+	"ko":    "韩文",
+	"fr":    "法文",
+	"de":    "德文",
+	"es":    "西班牙文",
+	"pt":    "葡萄牙文",
+	"ru":    "俄文",
+	"it":    "意大利文",
+}
+
+// NormalizeLanguage 校验并规范化语言代码（统一为小写），空字符串返回默认语言
+func NormalizeLanguage(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(code, "_", "-")))
+	if code == "" {
+		return defaultLanguage, nil
+	}
+	if len(code) > 10 || !languageCodePattern.MatchString(code) {
+		return "", fmt.Errorf("无效的语言代码: %s", code)
+	}
+	return code, nil
+}
+
+// articleLanguage 校验文章的语言代码，为空时使用站点默认语言
+func articleLanguage(code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		settings, _ := GetSettings()
+		return siteLanguage(settings), nil
+	}
+	return NormalizeLanguage(code)
+}
+
+// languageName 语言名称，未知语言返回代码本身
+func languageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// localizedSettingKeys 可按语言设置的站点设置，各语言的值保存在 "键.语言" 中，如 site_name.en
+var localizedSettingKeys = []string{"site_name", "site_subtitle", "site_description", "seo_description", "seo_keywords"}
+
+// LanguageInfo 站点已发布文章使用的语言
+type LanguageInfo struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	Articles  int64  `json:"articles"`   // 已发布文章数
+	IsDefault bool   `json:"is_default"` // 是否为站点默认语言
+}
+
+// siteLanguage 站点默认语言，由设置 default_language 指定
+func siteLanguage(settings map[string]string) string {
+	if language, err := NormalizeLanguage(settings["default_language"]); err == nil {
+		return language
+	}
+	return defaultLanguage
+}
+
+// LocalizeSettings 返回用指定语言的值覆盖后的设置，该语言未单独设置的项保留默认值
+func LocalizeSettings(settings map[string]string, language string) map[string]string {
+	result := make(map[string]string, len(settings))
+	for key, value := range settings {
+		result[key] = value
+	}
+	if language == "" {
+		return result
+	}
+	for _, key := range localizedSettingKeys {
+		if value := settings[key+"."+language]; value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// languageHomePath 语言首页的路径，默认语言为 /，其他语言为 /语言/
+func languageHomePath(language, siteLang string) string {
+	if language == "" || language == siteLang {
+		return "/"
+	}
+	return "/" + language + "/"
+}
+
+// GetLanguages 获取已发布文章使用的语言及文章数，默认语言排在最前
+func GetLanguages() ([]LanguageInfo, error) {
+	settings, err := GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	siteLang := siteLanguage(settings)
+
+	var rows []struct {
+		Language string
+		Count    int64
+	}
+	if err := database.DB.Model(&models.Article{}).
+		Select("language, COUNT(*) AS count").
+		Where("status = ?", "published").
+		Group("language").
+		Order("language").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	languages := []LanguageInfo{{Code: siteLang, Name: languageName(siteLang), IsDefault: true}}
+	for _, row := range rows {
+		if row.Language == siteLang {
+			languages[0].Articles = row.Count
+			continue
+		}
+		languages = append(languages, LanguageInfo{Code: row.Language, Name: languageName(row.Language), Articles: row.Count})
+	}
+	return languages, nil
+}
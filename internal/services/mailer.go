@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+//go:embed templates/mail/*.html
+var mailTemplates embed.FS
+
+const (
+	defaultMailMaxAttempts  = 5
+	defaultMailPollInterval = 10 * time.Second
+	mailRetryBaseDelay      = time.Minute
+	mailRetryMaxDelay       = 6 * time.Hour
+	mailBatchSize           = 20
+	maxMailSubjectLength    = 255 // 与 mail_queue.subject 字段长度一致
+	mailSubjectTitleLength  = 200 // 标题中引用文章标题时的最大长度
+)
+
+// MailSender 邮件发送接口
+type MailSender interface {
+	Send(to, subject, htmlBody string) error
+}
+
+// SMTPSender 基于SMTP的邮件发送实现
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     mail.Address
+	useTLS   bool
+}
+
+// NewSMTPSender 创建SMTP邮件发送实例
+func NewSMTPSender(cfg config.MailConfig) *SMTPSender {
+	return &SMTPSender{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     mail.Address{Name: cfg.FromName, Address: cfg.From},
+		useTLS:   cfg.TLS,
+	}
+}
+
+// Send 发送HTML邮件
+func (s *SMTPSender) Send(to, subject, htmlBody string) error {
+	// 收件人可能带显示名称，RCPT 命令只接受邮箱地址
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("无效的邮箱地址: %s", to)
+	}
+	addr := net.JoinHostPort(s.host, fmt.Sprintf("%d", s.port))
+
+	var conn net.Conn
+	if s.useTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, &tls.Config{ServerName: s.host})
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, 30*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("创建SMTP客户端失败: %w", err)
+	}
+	defer client.Close()
+
+	// 服务器支持时升级为加密连接
+	if !s.useTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return fmt.Errorf("STARTTLS失败: %w", err)
+			}
+		}
+	}
+
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return fmt.Errorf("设置发件人失败: %w", err)
+	}
+	if err := client.Rcpt(rcpt.Address); err != nil {
+		return fmt.Errorf("设置收件人失败: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("发送邮件数据失败: %w", err)
+	}
+	if _, err := w.Write(s.buildMessage(rcpt.String(), subject, htmlBody)); err != nil {
+		w.Close()
+		return fmt.Errorf("发送邮件数据失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件数据失败: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage 构建MIME邮件内容
+func (s *SMTPSender) buildMessage(to, subject, htmlBody string) []byte {
+	var msg bytes.Buffer
+
+	msg.WriteString("From: " + s.from.String() + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("Message-ID: " + messageID(s.from.Address) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+	msg.WriteString("\r\n")
+
+	// 按RFC 2045要求每76个字符换行
+	encoded := base64.StdEncoding.EncodeToString([]byte(htmlBody))
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+
+	return msg.Bytes()
+}
+
+// messageID 生成邮件 Message-ID
+func messageID(from string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
+
+// MailWorker 邮件发送队列处理器
+type MailWorker struct {
+	sender      MailSender
+	maxAttempts int
+	interval    time.Duration
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+var mailWorker *MailWorker
+
+// InitMailer 初始化邮件服务，未启用时邮件不会入队
+func InitMailer() {
+	cfg := config.AppConfig.Mail
+	if !cfg.Enabled {
+		return
+	}
+	StartMailWorker(NewSMTPSender(cfg))
+}
+
+// StartMailWorker 使用指定的发送实现启动邮件队列处理
+func StartMailWorker(sender MailSender) {
+	cfg := config.AppConfig.Mail
+
+	maxAttempts := defaultMailMaxAttempts
+	if cfg.MaxAttempts > 0 {
+		maxAttempts = cfg.MaxAttempts
+	}
+	interval := defaultMailPollInterval
+	if cfg.PollInterval > 0 {
+		interval = time.Duration(cfg.PollInterval) * time.Second
+	}
+
+	mailWorker = &MailWorker{
+		sender:      sender,
+		maxAttempts: maxAttempts,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go mailWorker.run()
+}
+
+// StopMailer 停止邮件队列处理，未发送的邮件保留在队列中
+func StopMailer() {
+	if mailWorker == nil {
+		return
+	}
+	close(mailWorker.stop)
+	<-mailWorker.done
+}
+
+// MailEnabled 邮件服务是否可用
+func MailEnabled() bool {
+	return mailWorker != nil
+}
+
+// EnqueueMail 渲染模板并加入发送队列
+func EnqueueMail(to, subject, templateName string, data map[string]interface{}) error {
+	if !MailEnabled() {
+		return nil
+	}
+	if _, err := mail.ParseAddress(to); err != nil {
+		return fmt.Errorf("无效的邮箱地址: %s", to)
+	}
+	subject = truncateString(subject, maxMailSubjectLength)
+
+	body, err := renderMail(templateName, subject, data)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Create(&models.MailQueue{
+		To:            to,
+		Subject:       subject,
+		Body:          body,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// renderMail 使用通用布局渲染邮件模板
+func renderMail(templateName, subject string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.ParseFS(mailTemplates, "templates/mail/layout.html", "templates/mail/"+templateName+".html")
+	if err != nil {
+		return "", fmt.Errorf("加载邮件模板失败: %w", err)
+	}
+
+	values := map[string]interface{}{
+		"Subject":  subject,
+		"SiteName": siteName(),
+		"SiteURL":  siteURL(),
+	}
+	for k, v := range data {
+		values[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", values); err != nil {
+		return "", fmt.Errorf("渲染邮件模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// run 定期处理发送队列
+func (w *MailWorker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.processQueue()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// processQueue 发送到期的邮件，失败时按指数退避重试
+func (w *MailWorker) processQueue() {
+	var mails []models.MailQueue
+	if err := database.DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+		Order("id ASC").
+		Limit(mailBatchSize).
+		Find(&mails).Error; err != nil {
+		log.Printf("读取邮件队列失败: %v", err)
+		return
+	}
+
+	for _, m := range mails {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		m.Attempts++
+		if err := w.sender.Send(m.To, m.Subject, m.Body); err != nil {
+			m.LastError = truncateString(err.Error(), 1000)
+			if m.Attempts >= w.maxAttempts {
+				m.Status = "failed"
+				log.Printf("邮件发送失败，已放弃（ID: %d）: %v", m.ID, err)
+			} else {
+				m.NextAttemptAt = time.Now().Add(backoffDelay(m.Attempts, mailRetryBaseDelay, mailRetryMaxDelay))
+			}
+		} else {
+			now := time.Now()
+			m.Status = "sent"
+			m.SentAt = &now
+			m.LastError = ""
+		}
+
+		if err := database.DB.Save(&m).Error; err != nil {
+			log.Printf("更新邮件队列失败（ID: %d）: %v", m.ID, err)
+		}
+	}
+}
+
+// backoffDelay 计算第 attempts 次失败后的重试等待时间（指数退避）
+func backoffDelay(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// siteName 获取站点名称
+func siteName() string {
+	if database.DB == nil {
+		return "Go Blog"
+	}
+	name, err := GetSettingByKey("site_name")
+	if err != nil || name == "" {
+		return "Go Blog"
+	}
+	return name
+}
+
+// siteURL 获取站点访问地址
+func siteURL() string {
+	if config.AppConfig == nil || config.AppConfig.Server.SiteURL == "" {
+		return "http://localhost:8080"
+	}
+	return strings.TrimRight(config.AppConfig.Server.SiteURL, "/")
+}
+
+// articleURL 文章页面的完整地址，与静态站点生成的页面路径一致（以 / 结尾）
+func articleURL(id uint) string {
+	return fmt.Sprintf("%s/article/%d/", siteURL(), id)
+}
+
+// truncateString 按字符数截断字符串
+func truncateString(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return string(runes[:maxLength])
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// markdown 静态站点使用的渲染器：CommonMark + GFM（表格、删除线、任务列表、自动链接），
+// 与前端 react-markdown + remark-gfm 支持的语法相同；正文中的原始HTML不输出
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// RenderMarkdown 将Markdown渲染为HTML，标题锚点与文章目录一致
+func RenderMarkdown(content string) template.HTML {
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	if err := markdown.Convert([]byte(content), &buf, parser.WithContext(ctx)); err != nil {
+		log.Printf("渲染Markdown失败: %v", err)
+		return template.HTML(template.HTMLEscapeString(content))
+	}
+	return template.HTML(buf.String())
+}
+
+// headingIDs 按 buildTOC 的规则生成标题锚点，重复时追加序号
+type headingIDs struct {
+	used map[string]int
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]int)}
+}
+
+// Generate 实现 parser.IDs 接口
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	text := stripInlineMarkdown(string(value))
+	if text == "" {
+		// 目录中不包含空标题，不占用序号
+		return []byte("heading")
+	}
+	anchor := slugify(text)
+	if n, ok := h.used[anchor]; ok {
+		h.used[anchor] = n + 1
+		anchor = fmt.Sprintf("%s-%d", anchor, n+1)
+	} else {
+		h.used[anchor] = 0
+	}
+	return []byte(anchor)
+}
+
+// Put 实现 parser.IDs 接口，记录手动指定的锚点
+func (h *headingIDs) Put(value []byte) {
+	h.used[string(value)] = 0
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+const (
+	digestCheckInterval = 10 * time.Minute
+)
+
+// SubscribeRequest 订阅请求
+type SubscribeRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// SubscriberListQuery 订阅者列表查询参数
+type SubscriberListQuery struct {
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+	Status   string `form:"status"`
+}
+
+// SubscriberListResponse 订阅者列表响应
+type SubscriberListResponse struct {
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+	List     []models.Subscriber `json:"list"`
+}
+
+// digestArticle 摘要邮件中的文章
+type digestArticle struct {
+	Title   string
+	Summary string
+	URL     string
+}
+
+var digestStop, digestDone chan struct{}
+
+// newsletterEnabled 是否启用邮件订阅
+func newsletterEnabled() bool {
+	return config.AppConfig != nil && config.AppConfig.Newsletter.Enabled && MailEnabled()
+}
+
+// Subscribe 订阅，发送确认邮件（双重确认）
+func Subscribe(email string) error {
+	if !newsletterEnabled() {
+		return errors.New("邮件订阅未启用")
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return errors.New("邮箱格式不正确")
+	}
+	email = strings.ToLower(addr.Address)
+
+	var subscriber models.Subscriber
+	err = database.DB.Where("email = ?", email).First(&subscriber).Error
+	if err == nil && subscriber.Status == "active" {
+		return nil
+	}
+
+	subscriber.Email = email
+	subscriber.Status = "pending"
+	subscriber.ConfirmToken = randomToken(32)
+	if subscriber.UnsubscribeToken == "" {
+		subscriber.UnsubscribeToken = randomToken(32)
+	}
+	if err := database.DB.Save(&subscriber).Error; err != nil {
+		return err
+	}
+
+	return EnqueueMail(email, "请确认订阅 "+siteName(), "subscribe_confirm", map[string]interface{}{
+		"ConfirmURL": fmt.Sprintf("%s/api/newsletter/confirm?token=%s", siteURL(), subscriber.ConfirmToken),
+	})
+}
+
+// ConfirmSubscription 确认订阅
+func ConfirmSubscription(token string) error {
+	if token == "" {
+		return errors.New("无效的确认链接")
+	}
+
+	var subscriber models.Subscriber
+	if err := database.DB.Where("confirm_token = ?", token).First(&subscriber).Error; err != nil {
+		return errors.New("无效的确认链接")
+	}
+
+	now := time.Now()
+	return database.DB.Model(&subscriber).Updates(map[string]interface{}{
+		"status":        "active",
+		"confirm_token": "",
+		"confirmed_at":  now,
+	}).Error
+}
+
+// Unsubscribe 退订
+func Unsubscribe(token string) error {
+	if token == "" {
+		return errors.New("无效的退订链接")
+	}
+
+	var subscriber models.Subscriber
+	if err := database.DB.Where("unsubscribe_token = ?", token).First(&subscriber).Error; err != nil {
+		return errors.New("无效的退订链接")
+	}
+
+	return database.DB.Model(&subscriber).Update("status", "unsubscribed").Error
+}
+
+// GetSubscribers 获取订阅者列表
+func GetSubscribers(query SubscriberListQuery) (*SubscriberListResponse, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	db := database.DB.Model(&models.Subscriber{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	db.Count(&total)
+
+	var subscribers []models.Subscriber
+	offset := (query.Page - 1) * query.PageSize
+	if err := db.Order("created_at DESC").
+		Limit(query.PageSize).Offset(offset).
+		Find(&subscribers).Error; err != nil {
+		return nil, err
+	}
+
+	return &SubscriberListResponse{
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		List:     subscribers,
+	}, nil
+}
+
+// GetAllSubscribers 获取全部订阅者（用于导出）
+func GetAllSubscribers(status string) ([]models.Subscriber, error) {
+	db := database.DB.Model(&models.Subscriber{})
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var subscribers []models.Subscriber
+	if err := db.Order("id ASC").Find(&subscribers).Error; err != nil {
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+// NotifySubscribers 文章发布后推送给已确认的订阅者，每篇文章只推送一次
+func NotifySubscribers(articleID uint) {
+	if !newsletterEnabled() || !config.AppConfig.Newsletter.SendOnPublish {
+		return
+	}
+
+	// 先标记再推送，避免重复发布时重复推送
+	now := time.Now()
+	result := database.DB.Model(&models.Article{}).
+		Where("id = ? AND status = ? AND newsletter_sent_at IS NULL", articleID, "published").
+		UpdateColumn("newsletter_sent_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	var article models.Article
+	if err := database.DB.First(&article, articleID).Error; err != nil {
+		return
+	}
+
+	subscribers, err := GetAllSubscribers("active")
+	if err != nil {
+		log.Printf("读取订阅者失败: %v", err)
+		return
+	}
+
+	subject := fmt.Sprintf("新文章：%s", truncateString(article.Title, mailSubjectTitleLength))
+	for _, subscriber := range subscribers {
+		err := EnqueueMail(subscriber.Email, subject, "new_post", map[string]interface{}{
+			"ArticleTitle":   article.Title,
+			"ArticleURL":     articleURL(article.ID),
+			"Summary":        article.Summary,
+			"UnsubscribeURL": unsubscribeURL(&subscriber),
+		})
+		if err != nil {
+			log.Printf("新文章邮件入队失败（%s）: %v", subscriber.Email, err)
+		}
+	}
+}
+
+// InitNewsletter 启动每周摘要任务
+func InitNewsletter() {
+	cfg := config.AppConfig.Newsletter
+	if !cfg.Enabled || !cfg.Digest.Enabled || !MailEnabled() {
+		return
+	}
+
+	digestStop = make(chan struct{})
+	digestDone = make(chan struct{})
+
+	go func() {
+		defer close(digestDone)
+
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if digestDue(time.Now()) {
+					if err := SendWeeklyDigest(); err != nil {
+						log.Printf("发送每周摘要失败: %v", err)
+					}
+				}
+			case <-digestStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopNewsletter 停止每周摘要任务
+func StopNewsletter() {
+	if digestStop == nil {
+		return
+	}
+	close(digestStop)
+	<-digestDone
+}
+
+// digestDue 判断是否到了发送每周摘要的时间
+func digestDue(now time.Time) bool {
+	cfg := config.AppConfig.Newsletter.Digest
+	if int(now.Weekday()) != cfg.Weekday || now.Hour() != cfg.Hour {
+		return false
+	}
+
+	var last models.NewsletterDigest
+	if err := database.DB.Order("created_at DESC").First(&last).Error; err != nil {
+		return true
+	}
+	return now.Sub(last.CreatedAt) > 24*time.Hour
+}
+
+// SendWeeklyDigest 将最近7天发布的文章汇总发送给订阅者
+func SendWeeklyDigest() error {
+	now := time.Now()
+	// 先写入发送记录，避免同一时段重复发送
+	digest := models.NewsletterDigest{CreatedAt: now}
+	if err := database.DB.Create(&digest).Error; err != nil {
+		return err
+	}
+
+	var articles []models.Article
+	if err := database.DB.Where("status = ? AND published_at >= ?", "published", now.AddDate(0, 0, -7)).
+		Order("published_at DESC").
+		Find(&articles).Error; err != nil {
+		return err
+	}
+	if len(articles) == 0 {
+		return nil
+	}
+
+	items := make([]digestArticle, 0, len(articles))
+	for _, article := range articles {
+		items = append(items, digestArticle{
+			Title:   article.Title,
+			Summary: article.Summary,
+			URL:     articleURL(article.ID),
+		})
+	}
+
+	subscribers, err := GetAllSubscribers("active")
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("%s 本周更新", siteName())
+	for _, subscriber := range subscribers {
+		err := EnqueueMail(subscriber.Email, subject, "digest", map[string]interface{}{
+			"Articles":       items,
+			"UnsubscribeURL": unsubscribeURL(&subscriber),
+		})
+		if err != nil {
+			log.Printf("每周摘要邮件入队失败（%s）: %v", subscriber.Email, err)
+			continue
+		}
+		digest.Recipients++
+	}
+
+	return database.DB.Model(&digest).Updates(map[string]interface{}{
+		"articles":   len(items),
+		"recipients": digest.Recipients,
+	}).Error
+}
+
+// unsubscribeURL 生成退订链接
+func unsubscribeURL(subscriber *models.Subscriber) string {
+	return fmt.Sprintf("%s/api/newsletter/unsubscribe?token=%s", siteURL(), subscriber.UnsubscribeToken)
+}
+
+// randomToken 生成 n 字节随机数的十六进制字符串
+func randomToken(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
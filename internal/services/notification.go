@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// replyNotifyLimit 每个邮箱 24 小时内最多收到的回复提醒数，避免借评论向任意邮箱反复发信
+const replyNotifyLimit = 5
+
+// NotifyNewComment 发送新评论通知
+// 文章作者收到新评论提醒；回复评论时，被回复者收到回复提醒
+func NotifyNewComment(comment *models.Comment) {
+	if !MailEnabled() {
+		return
+	}
+
+	var article models.Article
+	if err := database.DB.Preload("Author").First(&article, comment.ArticleID).Error; err != nil {
+		return
+	}
+
+	link := articleURL(article.ID)
+	// 邮件标题最长 255 字，长标题截断后再拼接
+	subjectTitle := truncateString(article.Title, mailSubjectTitleLength)
+	commenterEmail := strings.ToLower(strings.TrimSpace(comment.Email))
+
+	// 通知文章作者（作者自己评论时不通知）
+	authorEmail := strings.TrimSpace(article.Author.Email)
+	if authorEmail != "" && strings.ToLower(authorEmail) != commenterEmail {
+		err := EnqueueMail(authorEmail, fmt.Sprintf("《%s》收到新评论", subjectTitle), "new_comment", map[string]interface{}{
+			"ArticleTitle": article.Title,
+			"ArticleURL":   link,
+			"Nickname":     comment.Nickname,
+			"Content":      comment.Content,
+		})
+		if err != nil {
+			log.Printf("新评论通知入队失败: %v", err)
+		}
+	}
+
+	// 通知被回复的评论者
+	if comment.ParentID == nil {
+		return
+	}
+	var parent models.Comment
+	if err := database.DB.First(&parent, *comment.ParentID).Error; err != nil {
+		return
+	}
+	parentEmail := strings.TrimSpace(parent.Email)
+	if parentEmail == "" || strings.ToLower(parentEmail) == commenterEmail ||
+		strings.EqualFold(parentEmail, authorEmail) {
+		return
+	}
+	var replies int64
+	if err := database.DB.Table("comments").
+		Joins("JOIN comments AS parents ON parents.id = comments.parent_id").
+		Where("LOWER(parents.email) = ? AND comments.created_at >= ?", strings.ToLower(parentEmail), time.Now().Add(-24*time.Hour)).
+		Count(&replies).Error; err != nil {
+		log.Printf("统计评论回复数失败: %v", err)
+		return
+	}
+	if replies > replyNotifyLimit {
+		// 当前回复已计入
+		return
+	}
+
+	err := EnqueueMail(parentEmail, fmt.Sprintf("你在《%s》中的评论收到了回复", subjectTitle), "comment_reply", map[string]interface{}{
+		"ArticleTitle":   article.Title,
+		"ArticleURL":     link,
+		"ParentNickname": parent.Nickname,
+		"ParentContent":  parent.Content,
+		"Nickname":       comment.Nickname,
+		"Content":        comment.Content,
+	})
+	if err != nil {
+		log.Printf("评论回复通知入队失败: %v", err)
+	}
+}
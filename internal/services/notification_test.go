@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/testutil"
+)
+
+func TestReplyNotificationsCappedPerRecipient(t *testing.T) {
+	testutil.SetupDB(t)
+	previous := mailWorker
+	mailWorker = &MailWorker{}
+	t.Cleanup(func() { mailWorker = previous })
+
+	author := testutil.CreateUser(t, "author")
+	article := models.Article{Title: "并发模式", Content: "正文", Status: "published", AuthorID: author.ID}
+	database.DB.Create(&article)
+	parent := models.Comment{ArticleID: article.ID, Nickname: "读者", Email: "Reader@example.com", Content: "提问"}
+	database.DB.Create(&parent)
+
+	for i := 0; i < replyNotifyLimit+2; i++ {
+		reply := models.Comment{ArticleID: article.ID, ParentID: &parent.ID, Nickname: "访客", Content: "回复"}
+		database.DB.Create(&reply)
+		NotifyNewComment(&reply)
+	}
+
+	var sent int64
+	database.DB.Model(&models.MailQueue{}).Where("`to` = ?", "Reader@example.com").Count(&sent)
+	if sent != replyNotifyLimit {
+		t.Errorf("回复提醒应不超过 %d 封，实际 %d", replyNotifyLimit, sent)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-blog/internal/config"
+)
+
+// ollamaProvider Ollama 风格的本地模型服务（/api/chat 接口）
+type ollamaProvider struct {
+	apiURL      string
+	model       string
+	maxTokens   int
+	temperature float64
+	idleTimeout time.Duration
+	httpClient  *http.Client
+}
+
+// newOllamaProvider 创建本地模型提供方
+func newOllamaProvider(cfg config.OllamaConfig, idleTimeout time.Duration) *ollamaProvider {
+	apiURL := strings.TrimRight(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = "http://localhost:11434"
+	}
+	return &ollamaProvider{
+		apiURL:      apiURL,
+		model:       cfg.Model,
+		maxTokens:   cfg.MaxTokens,
+		temperature: cfg.Temperature,
+		idleTimeout: idleTimeout,
+		httpClient:  newAIHTTPClient(idleTimeout),
+	}
+}
+
+// ollamaRequest /api/chat 请求结构
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Options  ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaOptions 模型参数
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// ollamaResponse /api/chat 响应结构，流式模式下每行一个
+type ollamaResponse struct {
+	Message chatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error"`
+	// 最后一行返回提示词和生成内容的 token 数
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// tokenUsage 响应中的用量
+func (r *ollamaResponse) tokenUsage() TokenUsage {
+	return TokenUsage{PromptTokens: r.PromptEvalCount, CompletionTokens: r.EvalCount}
+}
+
+// complete 调用本地模型接口
+func (s *ollamaProvider) complete(ctx context.Context, prompt string) (string, TokenUsage, error) {
+	resp, err := s.post(ctx, prompt, false)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return "", TokenUsage{}, fmt.Errorf("解析响应失败: %w, 响应: %s", err, string(body))
+	}
+	if ollamaResp.Error != "" {
+		return "", TokenUsage{}, fmt.Errorf("API返回错误: %s", ollamaResp.Error)
+	}
+	if ollamaResp.Message.Content == "" {
+		return "", ollamaResp.tokenUsage(), fmt.Errorf("API返回空内容")
+	}
+
+	return ollamaResp.Message.Content, ollamaResp.tokenUsage(), nil
+}
+
+// stream 流式调用本地模型接口，响应为逐行的 JSON
+func (s *ollamaProvider) stream(ctx context.Context, prompt string, callback func(string) error) (TokenUsage, error) {
+	resp, err := s.post(ctx, prompt, true)
+	if err != nil {
+		return TokenUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var ollamaResp ollamaResponse
+		if err := json.Unmarshal(line, &ollamaResp); err != nil {
+			continue
+		}
+		if ollamaResp.Error != "" {
+			return TokenUsage{}, fmt.Errorf("API返回错误: %s", ollamaResp.Error)
+		}
+		if ollamaResp.Message.Content != "" {
+			if err := callback(ollamaResp.Message.Content); err != nil {
+				return TokenUsage{}, err
+			}
+		}
+		if ollamaResp.Done {
+			return ollamaResp.tokenUsage(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return TokenUsage{}, fmt.Errorf("读取流数据失败: %w", err)
+	}
+	return TokenUsage{}, nil
+}
+
+// post 发送聊天请求，非 200 响应返回错误
+func (s *ollamaProvider) post(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
+	jsonData, err := json.Marshal(ollamaRequest{
+		Model:    s.model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+		Stream:   stream,
+		Options: ollamaOptions{
+			Temperature: s.temperature,
+			NumPredict:  s.maxTokens,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", s.apiURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := doAIRequest(ctx, s.httpClient, httpReq, s.idleTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("API请求失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("API返回错误: %s, 响应: %s", resp.Status, string(body))
+	}
+	return resp, nil
+}
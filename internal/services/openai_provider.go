@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// openAIProvider OpenAI 兼容接口（通义千问兼容模式、OpenAI、DeepSeek 等）
+type openAIProvider struct {
+	apiKey      string
+	apiURL      string
+	model       string
+	maxTokens   int
+	temperature float64
+	idleTimeout time.Duration
+	httpClient  *http.Client
+}
+
+// newOpenAIProvider 创建 OpenAI 兼容接口的提供方
+// apiURL 为接口根地址，如 https://api.openai.com/v1，也兼容直接填写 /chat/completions 完整地址
+func newOpenAIProvider(apiKey, apiURL, model string, maxTokens int, temperature float64, idleTimeout time.Duration) *openAIProvider {
+	apiURL = strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/chat/completions")
+	return &openAIProvider{
+		apiKey:      apiKey,
+		apiURL:      apiURL,
+		model:       model,
+		maxTokens:   maxTokens,
+		temperature: temperature,
+		idleTimeout: idleTimeout,
+		httpClient:  newAIHTTPClient(idleTimeout),
+	}
+}
+
+// chatCompletionRequest OpenAI兼容模式API请求结构
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+	// StreamOptions 流式模式下要求在最后一个数据块返回用量
+	StreamOptions *chatStreamOptions `json:"stream_options,omitempty"`
+}
+
+// chatStreamOptions 流式选项
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatMessage 消息结构
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletionResponse OpenAI兼容模式API响应结构
+type chatCompletionResponse struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// tokenUsage 响应中的用量，未返回时为零值
+func (r *chatCompletionResponse) tokenUsage() TokenUsage {
+	if r.Usage == nil {
+		return TokenUsage{}
+	}
+	return TokenUsage{PromptTokens: r.Usage.PromptTokens, CompletionTokens: r.Usage.CompletionTokens}
+}
+
+// complete 调用 OpenAI 兼容接口
+func (s *openAIProvider) complete(ctx context.Context, prompt string) (string, TokenUsage, error) {
+	// 构建请求 (OpenAI兼容格式)
+	reqBody := chatCompletionRequest{
+		Model: s.model,
+		Messages: []chatMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		MaxTokens:   s.maxTokens,
+		Temperature: s.temperature,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	// 创建HTTP请求
+	fullURL := s.apiURL + "/chat/completions"
+	httpReq, err := http.NewRequest("POST", fullURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	// 发送请求
+	resp, err := doAIRequest(ctx, s.httpClient, httpReq, s.idleTimeout)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("API请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", TokenUsage{}, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", TokenUsage{}, fmt.Errorf("API返回错误: %s, 响应: %s", resp.Status, string(body))
+	}
+
+	// 解析响应 (OpenAI兼容格式)
+	var chatResp chatCompletionResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", TokenUsage{}, fmt.Errorf("解析响应失败: %w, 响应: %s", err, string(body))
+	}
+
+	if len(chatResp.Choices) == 0 || chatResp.Choices[0].Message.Content == "" {
+		return "", chatResp.tokenUsage(), fmt.Errorf("API返回空内容")
+	}
+
+	return chatResp.Choices[0].Message.Content, chatResp.tokenUsage(), nil
+}
+
+// stream 流式调用 OpenAI 兼容接口
+func (s *openAIProvider) stream(ctx context.Context, prompt string, callback func(string) error) (TokenUsage, error) {
+	// 构建请求
+	reqBody := chatCompletionRequest{
+		Model: s.model,
+		Messages: []chatMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		MaxTokens:   s.maxTokens,
+		Temperature: s.temperature,
+		Stream:      true, // 开启流式模式
+		StreamOptions: &chatStreamOptions{
+			IncludeUsage: true,
+		},
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return TokenUsage{}, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	// 创建HTTP请求
+	fullURL := s.apiURL + "/chat/completions"
+	httpReq, err := http.NewRequest("POST", fullURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return TokenUsage{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+	httpReq.Header.Set("Accept", "text/event-stream") // Accept stream
+
+	// 发送请求
+	resp, err := doAIRequest(ctx, s.httpClient, httpReq, s.idleTimeout)
+	if err != nil {
+		return TokenUsage{}, fmt.Errorf("API请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return TokenUsage{}, fmt.Errorf("API返回错误: %s, 响应: %s", resp.Status, string(body))
+	}
+
+	// 读取流式响应，不支持返回用量的接口由调用方估算
+	var usage TokenUsage
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return usage, fmt.Errorf("读取流数据失败: %w", err)
+		}
+
+		// 处理 SSE 数据行
+		lineStr := strings.TrimSpace(string(line))
+		if lineStr == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(lineStr, "data: ") {
+			continue
+		}
+
+		data := strings.TrimPrefix(lineStr, "data: ")
+		if data == "[DONE]" {
+			break
+		}
+
+		var chatResp chatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chatResp); err != nil {
+			continue // 忽略解析错误，可能是心跳或其他数据
+		}
+
+		if chatResp.Usage != nil {
+			usage = chatResp.tokenUsage()
+		}
+		if len(chatResp.Choices) > 0 {
+			content := chatResp.Choices[0].Delta.Content
+			if content != "" {
+				if err := callback(content); err != nil {
+					return usage, err // 回调返回错误则停止流
+				}
+			}
+		}
+	}
+
+	return usage, nil
+}
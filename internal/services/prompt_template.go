@@ -0,0 +1,184 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrPromptTemplateNotFound 提示词模板名称不存在
+var ErrPromptTemplateNotFound = errors.New("提示词模板不存在")
+
+// PromptTemplateInfo 提示词模板概况
+type PromptTemplateInfo struct {
+	Name           string                 `json:"name"`
+	DefaultContent string                 `json:"default_content"` // 内置模板
+	Active         *models.PromptTemplate `json:"active"`          // 启用的版本，为空时使用内置模板
+}
+
+// PromptTemplateDetail 提示词模板及全部版本
+type PromptTemplateDetail struct {
+	PromptTemplateInfo
+	Versions []models.PromptTemplate `json:"versions"`
+}
+
+// GetPromptTemplates 获取所有提示词模板的当前状态
+func GetPromptTemplates() ([]PromptTemplateInfo, error) {
+	var active []models.PromptTemplate
+	if err := database.DB.Where("active = ?", true).Find(&active).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.PromptTemplate, len(active))
+	for i := range active {
+		byName[active[i].Name] = &active[i]
+	}
+
+	infos := make([]PromptTemplateInfo, 0, len(promptDefinitions))
+	for name, def := range promptDefinitions {
+		infos = append(infos, PromptTemplateInfo{
+			Name:           name,
+			DefaultContent: def.defaultContent,
+			Active:         byName[name],
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// GetPromptTemplate 获取提示词模板的全部版本，按版本号倒序
+func GetPromptTemplate(name string) (*PromptTemplateDetail, error) {
+	def, ok := promptDefinitions[name]
+	if !ok {
+		return nil, ErrPromptTemplateNotFound
+	}
+
+	detail := &PromptTemplateDetail{
+		PromptTemplateInfo: PromptTemplateInfo{Name: name, DefaultContent: def.defaultContent},
+	}
+	if err := database.DB.Where("name = ?", name).Order("version DESC").Find(&detail.Versions).Error; err != nil {
+		return nil, err
+	}
+	for i := range detail.Versions {
+		if detail.Versions[i].Active {
+			detail.Active = &detail.Versions[i]
+		}
+	}
+	return detail, nil
+}
+
+// SavePromptTemplate 保存为新版本并启用
+func SavePromptTemplate(name, content, note string, userID uint) (*models.PromptTemplate, error) {
+	if err := validatePromptTemplate(name, content); err != nil {
+		return nil, err
+	}
+
+	tmpl := models.PromptTemplate{
+		Name:      name,
+		Content:   content,
+		Note:      truncateString(strings.TrimSpace(note), 255),
+		Active:    true,
+		CreatedBy: userID,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.PromptTemplate{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("name = ?", name).
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		tmpl.Version = latest + 1
+
+		if err := tx.Model(&models.PromptTemplate{}).
+			Where("name = ? AND active = ?", name, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(&tmpl).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// ActivatePromptTemplate 启用指定版本，用于回滚
+func ActivatePromptTemplate(name string, version int) (*models.PromptTemplate, error) {
+	if _, ok := promptDefinitions[name]; !ok {
+		return nil, ErrPromptTemplateNotFound
+	}
+
+	var tmpl models.PromptTemplate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("name = ? AND version = ?", name, version).First(&tmpl).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("版本不存在")
+			}
+			return err
+		}
+		if err := tx.Model(&models.PromptTemplate{}).
+			Where("name = ? AND active = ?", name, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		tmpl.Active = true
+		return tx.Model(&tmpl).Update("active", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// ResetPromptTemplate 停用所有版本，恢复使用内置模板，历史版本保留
+func ResetPromptTemplate(name string) error {
+	if _, ok := promptDefinitions[name]; !ok {
+		return ErrPromptTemplateNotFound
+	}
+	return database.DB.Model(&models.PromptTemplate{}).
+		Where("name = ? AND active = ?", name, true).
+		Update("active", false).Error
+}
+
+// PreviewPrompt 使用示例请求渲染提示词
+// content 为空时预览当前使用的模板，data 为对应操作的请求 JSON
+func PreviewPrompt(name, content string, data json.RawMessage) (string, error) {
+	def, ok := promptDefinitions[name]
+	if !ok {
+		return "", ErrPromptTemplateNotFound
+	}
+
+	req := def.newRequest()
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, req); err != nil {
+			return "", fmt.Errorf("示例数据格式错误: %w", err)
+		}
+	}
+
+	if content == "" {
+		return buildPrompt(name, req), nil
+	}
+	return renderPrompt(name, content, def.data(req))
+}
+
+// validatePromptTemplate 校验模板能否解析，并能用空请求渲染（字段名错误会在此发现）
+func validatePromptTemplate(name, content string) error {
+	def, ok := promptDefinitions[name]
+	if !ok {
+		return ErrPromptTemplateNotFound
+	}
+	if strings.TrimSpace(content) == "" {
+		return errors.New("模板内容不能为空")
+	}
+	if _, err := renderPrompt(name, content, def.data(def.newRequest())); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReactionTargetArticle = "article"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionKinds 支持的表态类型
+var ReactionKinds = []string{"like", "love", "laugh", "wow", "sad", "clap"}
+
+// ReactionRequest 表态请求
+type ReactionRequest struct {
+	Kind string `json:"kind" binding:"required"`
+}
+
+// ReactionResult 表态结果
+type ReactionResult struct {
+	Reacted bool             `json:"reacted"` // 当前访客是否已表态
+	Counts  map[string]int64 `json:"counts"`
+}
+
+// ReactionFingerprint 生成访客的表态指纹，以 JWT 密钥作为盐值，数据库中的指纹无法反推出IP
+func ReactionFingerprint(ip, userAgent string) string {
+	return utils.KeyedFingerprint(config.AppConfig.JWT.Secret, "reaction", ip, userAgent)
+}
+
+// ToggleReaction 切换表态：未表态时添加，已表态时取消
+func ToggleReaction(targetType string, targetID uint, kind, fingerprint string) (*ReactionResult, error) {
+	if !containsString(ReactionKinds, kind) {
+		return nil, errors.New("不支持的表态类型")
+	}
+
+	switch targetType {
+	case ReactionTargetArticle:
+		var article models.Article
+		if err := database.DB.Where("status = ?", "published").First(&article, targetID).Error; err != nil {
+			return nil, errors.New("文章不存在")
+		}
+	case ReactionTargetComment:
+		var comment models.Comment
+		if err := database.DB.First(&comment, targetID).Error; err != nil {
+			return nil, errors.New("评论不存在")
+		}
+	default:
+		return nil, errors.New("不支持的表态对象")
+	}
+
+	reacted := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("target_type = ? AND target_id = ? AND kind = ? AND fingerprint = ?",
+			targetType, targetID, kind, fingerprint).Delete(&models.Reaction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		reacted = true
+		return tx.Create(&models.Reaction{
+			TargetType:  targetType,
+			TargetID:    targetID,
+			Kind:        kind,
+			Fingerprint: fingerprint,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := reactionCounts(targetType, []uint{targetID})
+	return &ReactionResult{
+		Reacted: reacted,
+		Counts:  counts[targetID],
+	}, nil
+}
+
+// AttachArticleReactions 为文章列表填充表态数量
+func AttachArticleReactions(articles []models.Article) {
+	ids := make([]uint, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	counts := reactionCounts(ReactionTargetArticle, ids)
+	for i := range articles {
+		articles[i].Reactions = counts[articles[i].ID]
+	}
+}
+
+// AttachCommentReactions 为评论列表填充表态数量
+func AttachCommentReactions(comments []models.Comment) {
+	ids := make([]uint, len(comments))
+	for i := range comments {
+		ids[i] = comments[i].ID
+	}
+
+	counts := reactionCounts(ReactionTargetComment, ids)
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+	}
+}
+
+// DeleteReactions 删除对象的全部表态
+func DeleteReactions(tx *gorm.DB, targetType string, targetID uint) error {
+	return tx.Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Delete(&models.Reaction{}).Error
+}
+
+// reactionCounts 批量统计表态数量，每个对象都返回全部表态类型
+func reactionCounts(targetType string, ids []uint) map[uint]map[string]int64 {
+	result := make(map[uint]map[string]int64, len(ids))
+	for _, id := range ids {
+		counts := make(map[string]int64, len(ReactionKinds))
+		for _, kind := range ReactionKinds {
+			counts[kind] = 0
+		}
+		result[id] = counts
+	}
+	if len(ids) == 0 {
+		return result
+	}
+
+	var rows []struct {
+		TargetID uint
+		Kind     string
+		Count    int64
+	}
+	database.DB.Model(&models.Reaction{}).
+		Select("target_id, kind, COUNT(*) AS count").
+		Where("target_type = ? AND target_id IN ?", targetType, ids).
+		Group("target_id, kind").
+		Scan(&rows)
+
+	for _, row := range rows {
+		if counts, ok := result[row.TargetID]; ok {
+			counts[row.Kind] = row.Count
+		}
+	}
+
+	return result
+}
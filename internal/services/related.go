@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+const (
+	defaultRelatedLimit    = 5
+	defaultRelatedCacheTTL = 10 * time.Minute
+	// 共同标签的权重高于共同分类
+	relatedTagWeight      = 2.0
+	relatedCategoryWeight = 1.0
+)
+
+// RelatedArticle 相关文章
+type RelatedArticle struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+	Score     float64   `json:"score"`
+}
+
+type relatedCacheEntry struct {
+	items     []RelatedArticle
+	expiresAt time.Time
+}
+
+var (
+	relatedCacheMu sync.RWMutex
+	relatedCache   = make(map[uint]relatedCacheEntry)
+)
+
+// InvalidateRelatedCache 清空相关文章缓存
+// 任意文章变化都会影响其他文章的得分，因此整体清空
+func InvalidateRelatedCache() {
+	relatedCacheMu.Lock()
+	relatedCache = make(map[uint]relatedCacheEntry)
+	relatedCacheMu.Unlock()
+}
+
+// GetRelatedArticles 获取相关文章
+// 只在同一语言的文章中，根据共同标签和分类计算得分，可按配置混合 TF-IDF 内容相似度
+func GetRelatedArticles(id uint, limit int) ([]RelatedArticle, error) {
+	cfg := relatedConfig()
+	if limit <= 0 || limit > 20 {
+		limit = cfg.Limit
+	}
+
+	relatedCacheMu.RLock()
+	entry, ok := relatedCache[id]
+	relatedCacheMu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return limitRelated(entry.items, limit), nil
+	}
+
+	var article models.Article
+	if err := database.DB.Preload("Categories").Preload("Tags").
+		Where("status = ?", "published").First(&article, id).Error; err != nil {
+		return nil, errors.New("文章不存在")
+	}
+
+	// 只推荐同一语言的文章
+	var candidates []models.Article
+	if err := database.DB.Preload("Categories").Preload("Tags").
+		Where("status = ? AND id <> ? AND language = ?", "published", id, article.Language).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	items := scoreRelatedArticles(&article, candidates, cfg.TFIDFWeight)
+
+	// 缓存完整结果，不同 limit 的请求共用
+	relatedCacheMu.Lock()
+	relatedCache[id] = relatedCacheEntry{
+		items:     items,
+		expiresAt: time.Now().Add(cfg.CacheTTL),
+	}
+	relatedCacheMu.Unlock()
+
+	return limitRelated(items, limit), nil
+}
+
+type relatedSettings struct {
+	Limit       int
+	TFIDFWeight float64
+	CacheTTL    time.Duration
+}
+
+// relatedConfig 读取相关文章配置并补全默认值
+func relatedConfig() relatedSettings {
+	settings := relatedSettings{
+		Limit:    defaultRelatedLimit,
+		CacheTTL: defaultRelatedCacheTTL,
+	}
+	if config.AppConfig == nil {
+		return settings
+	}
+
+	cfg := config.AppConfig.Related
+	if cfg.Limit > 0 {
+		settings.Limit = cfg.Limit
+	}
+	if cfg.CacheTTL > 0 {
+		settings.CacheTTL = time.Duration(cfg.CacheTTL) * time.Second
+	}
+	settings.TFIDFWeight = math.Max(0, math.Min(1, cfg.TFIDFWeight))
+	return settings
+}
+
+// scoreRelatedArticles 计算候选文章得分，结果按得分降序排列
+func scoreRelatedArticles(article *models.Article, candidates []models.Article, tfidfWeight float64) []RelatedArticle {
+	tagIDs := make(map[uint]bool, len(article.Tags))
+	for _, tag := range article.Tags {
+		tagIDs[tag.ID] = true
+	}
+	categoryIDs := make(map[uint]bool, len(article.Categories))
+	for _, category := range article.Categories {
+		categoryIDs[category.ID] = true
+	}
+	maxTaxonomy := relatedTagWeight*float64(len(tagIDs)) + relatedCategoryWeight*float64(len(categoryIDs))
+
+	var similarities []float64
+	if tfidfWeight > 0 {
+		docs := make([]string, 0, len(candidates)+1)
+		docs = append(docs, articleText(article))
+		for i := range candidates {
+			docs = append(docs, articleText(&candidates[i]))
+		}
+		similarities = tfidfSimilarities(docs)
+	}
+
+	items := make([]RelatedArticle, 0, len(candidates))
+	for i, candidate := range candidates {
+		taxonomy := 0.0
+		for _, tag := range candidate.Tags {
+			if tagIDs[tag.ID] {
+				taxonomy += relatedTagWeight
+			}
+		}
+		for _, category := range candidate.Categories {
+			if categoryIDs[category.ID] {
+				taxonomy += relatedCategoryWeight
+			}
+		}
+		if maxTaxonomy > 0 {
+			taxonomy /= maxTaxonomy
+		}
+
+		score := taxonomy
+		if similarities != nil {
+			score = (1-tfidfWeight)*taxonomy + tfidfWeight*similarities[i]
+		}
+		if score <= 0 {
+			continue
+		}
+
+		items = append(items, RelatedArticle{
+			ID:        candidate.ID,
+			Title:     candidate.Title,
+			Summary:   candidate.Summary,
+			CreatedAt: candidate.CreatedAt,
+			Score:     math.Round(score*10000) / 10000,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	return items
+}
+
+// tfidfSimilarities 计算第一篇文档与其余文档的 TF-IDF 余弦相似度
+func tfidfSimilarities(docs []string) []float64 {
+	termFreqs := make([]map[string]float64, len(docs))
+	docFreq := make(map[string]int)
+	for i, doc := range docs {
+		tf := make(map[string]float64)
+		for _, term := range tokenize(doc) {
+			tf[term]++
+		}
+		for term := range tf {
+			docFreq[term]++
+		}
+		termFreqs[i] = tf
+	}
+
+	n := float64(len(docs))
+	vectors := make([]map[string]float64, len(docs))
+	norms := make([]float64, len(docs))
+	for i, tf := range termFreqs {
+		vec := make(map[string]float64, len(tf))
+		var norm float64
+		for term, freq := range tf {
+			weight := (1 + math.Log(freq)) * math.Log(1+n/float64(docFreq[term]))
+			vec[term] = weight
+			norm += weight * weight
+		}
+		vectors[i] = vec
+		norms[i] = math.Sqrt(norm)
+	}
+
+	result := make([]float64, len(docs)-1)
+	for i := 1; i < len(docs); i++ {
+		if norms[0] == 0 || norms[i] == 0 {
+			continue
+		}
+		var dot float64
+		for term, weight := range vectors[0] {
+			dot += weight * vectors[i][term]
+		}
+		result[i-1] = dot / (norms[0] * norms[i])
+	}
+
+	return result
+}
+
+// tokenize 分词：英文按单词切分，中日韩文字按相邻二元组切分
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var prevCJK rune
+
+	flushWord := func() {
+		if len(word) >= 2 {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			if prevCJK != 0 {
+				tokens = append(tokens, string([]rune{prevCJK, r}))
+			}
+			prevCJK = r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			prevCJK = 0
+			word = append(word, r)
+		default:
+			prevCJK = 0
+			flushWord()
+		}
+	}
+	flushWord()
+
+	return tokens
+}
+
+// articleText 获取用于计算相似度的文章纯文本
+func articleText(article *models.Article) string {
+	return article.Title + " " + strings.Join(markdownParagraphs(article.Content), " ")
+}
+
+// limitRelated 截取前 limit 条结果
+func limitRelated(items []RelatedArticle, limit int) []RelatedArticle {
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	result := make([]RelatedArticle, len(items))
+	copy(result, items)
+	return result
+}
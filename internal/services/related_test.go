@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"testing"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+	"go-blog/internal/services"
+	"go-blog/internal/testutil"
+)
+
+func TestRelatedArticlesSameLanguage(t *testing.T) {
+	testutil.SetupDB(t)
+	services.InvalidateRelatedCache()
+	author := testutil.CreateUser(t, "author")
+	tag := models.Tag{Name: "Go"}
+	database.DB.Create(&tag)
+
+	create := func(title, language string) uint {
+		t.Helper()
+		article := models.Article{Title: title, Content: "goroutine channel", Status: "published", Language: language, AuthorID: author.ID, Tags: []models.Tag{tag}}
+		if err := database.DB.Create(&article).Error; err != nil {
+			t.Fatalf("创建文章失败: %v", err)
+		}
+		return article.ID
+	}
+	source := create("并发模式", "zh")
+	sameLanguage := create("通道", "zh")
+	create("Channels", "en")
+
+	items, err := services.GetRelatedArticles(source, 10)
+	if err != nil {
+		t.Fatalf("获取相关文章失败: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != sameLanguage {
+		t.Errorf("只应推荐同一语言的文章: %+v", items)
+	}
+}
@@ -1,12 +1,23 @@
 package services
 
 import (
+	"strings"
+
 	"go-blog/internal/database"
 	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 搜索模式
+const (
+	SearchModeKeyword  = "keyword"  // 关键词匹配标题、正文和摘要
+	SearchModeSemantic = "semantic" // 按向量相似度排序
+	SearchModeHybrid   = "hybrid"   // 关键词和语义搜索结果按排名融合
 )
 
-// SearchArticles 搜索文章
-func SearchArticles(keyword string, page, pageSize int) (*ArticleListResponse, error) {
+// SearchArticles 搜索文章，language 不为空时只搜索该语言的文章
+func SearchArticles(keyword, language string, page, pageSize int) (*ArticleListResponse, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -14,13 +25,7 @@ func SearchArticles(keyword string, page, pageSize int) (*ArticleListResponse, e
 		pageSize = 10
 	}
 
-	db := database.DB.Model(&models.Article{}).Where("status = ?", "published")
-
-	if keyword != "" {
-		searchPattern := "%" + keyword + "%"
-		db = db.Where("title LIKE ? OR content LIKE ? OR summary LIKE ?",
-			searchPattern, searchPattern, searchPattern)
-	}
+	db := keywordSearchQuery(keyword, language)
 
 	var total int64
 	db.Count(&total)
@@ -35,6 +40,7 @@ func SearchArticles(keyword string, page, pageSize int) (*ArticleListResponse, e
 	if err != nil {
 		return nil, err
 	}
+	AttachArticleReactions(articles)
 
 	return &ArticleListResponse{
 		Total:    total,
@@ -43,3 +49,19 @@ func SearchArticles(keyword string, page, pageSize int) (*ArticleListResponse, e
 		List:     articles,
 	}, nil
 }
+
+// keywordSearchQuery 关键词搜索已发布文章的查询条件
+func keywordSearchQuery(keyword, language string) *gorm.DB {
+	db := database.DB.Model(&models.Article{}).Where("status = ?", "published")
+
+	if keyword != "" {
+		searchPattern := "%" + keyword + "%"
+		db = db.Where("title LIKE ? OR content LIKE ? OR summary LIKE ?",
+			searchPattern, searchPattern, searchPattern)
+	}
+
+	if language != "" {
+		db = db.Where("language = ?", strings.ToLower(language))
+	}
+	return db
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"errors"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SeriesRequest 创建/更新系列请求
+type SeriesRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+}
+
+// SeriesArticlesRequest 设置系列文章请求，按数组顺序排列
+type SeriesArticlesRequest struct {
+	ArticleIDs []uint `json:"article_ids"`
+}
+
+// SeriesListItem 系列列表项
+type SeriesListItem struct {
+	models.Series
+	ArticleCount int64 `json:"article_count"`
+}
+
+// ArticleBrief 文章简要信息
+type ArticleBrief struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// SeriesNavigation 系列内导航信息
+type SeriesNavigation struct {
+	ID       uint          `json:"id"`
+	Title    string        `json:"title"`
+	Position int           `json:"position"` // 当前文章在系列中的位置，从1开始
+	Total    int           `json:"total"`
+	Prev     *ArticleBrief `json:"prev"`
+	Next     *ArticleBrief `json:"next"`
+}
+
+// GetSeriesList 获取系列列表
+func GetSeriesList() ([]SeriesListItem, error) {
+	var series []models.Series
+	if err := database.DB.Order("created_at DESC").Find(&series).Error; err != nil {
+		return nil, err
+	}
+
+	// 统计每个系列已发布的文章数
+	type countRow struct {
+		SeriesID uint
+		Count    int64
+	}
+	var rows []countRow
+	database.DB.Model(&models.Article{}).
+		Select("series_id, COUNT(*) AS count").
+		Where("series_id IS NOT NULL AND status = ?", "published").
+		Group("series_id").
+		Scan(&rows)
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.SeriesID] = row.Count
+	}
+
+	list := make([]SeriesListItem, 0, len(series))
+	for _, s := range series {
+		list = append(list, SeriesListItem{Series: s, ArticleCount: counts[s.ID]})
+	}
+
+	return list, nil
+}
+
+// GetSeriesByID 获取系列详情，showAll 为 true 时包含草稿
+func GetSeriesByID(id uint, showAll bool) (*models.Series, error) {
+	var series models.Series
+	err := database.DB.Preload("Articles", func(db *gorm.DB) *gorm.DB {
+		if !showAll {
+			db = db.Where("status = ?", "published")
+		}
+		return db.Order("series_order ASC, id ASC")
+	}).First(&series, id).Error
+
+	if err != nil {
+		return nil, errors.New("系列不存在")
+	}
+
+	return &series, nil
+}
+
+// CreateSeries 创建系列
+func CreateSeries(req SeriesRequest) (*models.Series, error) {
+	series := models.Series{
+		Title:       req.Title,
+		Description: req.Description,
+	}
+
+	if err := database.DB.Create(&series).Error; err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// UpdateSeries 更新系列
+func UpdateSeries(id uint, req SeriesRequest) (*models.Series, error) {
+	var series models.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		return nil, errors.New("系列不存在")
+	}
+
+	series.Title = req.Title
+	series.Description = req.Description
+
+	if err := database.DB.Save(&series).Error; err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// DeleteSeries 删除系列，系列中的文章保留但移出系列
+func DeleteSeries(id uint) error {
+	var series models.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		return errors.New("系列不存在")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Article{}).Where("series_id = ?", id).
+			Updates(map[string]interface{}{"series_id": nil, "series_order": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&series).Error
+	})
+}
+
+// SetSeriesArticles 设置系列包含的文章及顺序
+// 不在列表中的原有文章会被移出系列，文章原属于其他系列时会被移入本系列
+func SetSeriesArticles(id uint, req SeriesArticlesRequest) (*models.Series, error) {
+	var series models.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		return nil, errors.New("系列不存在")
+	}
+
+	if len(uniqueIDs(req.ArticleIDs)) != len(req.ArticleIDs) {
+		return nil, errors.New("文章ID重复")
+	}
+
+	var count int64
+	if len(req.ArticleIDs) > 0 {
+		database.DB.Model(&models.Article{}).Where("id IN ?", req.ArticleIDs).Count(&count)
+	}
+	if int(count) != len(req.ArticleIDs) {
+		return nil, errors.New("包含不存在的文章")
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Article{}).Where("series_id = ?", id).
+			Updates(map[string]interface{}{"series_id": nil, "series_order": 0}).Error; err != nil {
+			return err
+		}
+
+		for i, articleID := range req.ArticleIDs {
+			if err := tx.Model(&models.Article{}).Where("id = ?", articleID).
+				Updates(map[string]interface{}{"series_id": id, "series_order": i + 1}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSeriesByID(id, true)
+}
+
+// getSeriesNavigation 获取文章所在系列的上一篇/下一篇导航
+func getSeriesNavigation(article *models.Article) *SeriesNavigation {
+	if article.SeriesID == nil {
+		return nil
+	}
+
+	var series models.Series
+	if err := database.DB.First(&series, *article.SeriesID).Error; err != nil {
+		return nil
+	}
+
+	var articles []ArticleBrief
+	database.DB.Model(&models.Article{}).
+		Select("id, title").
+		Where("series_id = ? AND (status = ? OR id = ?)", series.ID, "published", article.ID).
+		Order("series_order ASC, id ASC").
+		Scan(&articles)
+
+	nav := &SeriesNavigation{
+		ID:    series.ID,
+		Title: series.Title,
+		Total: len(articles),
+	}
+	for i, item := range articles {
+		if item.ID != article.ID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Prev = &articles[i-1]
+		}
+		if i < len(articles)-1 {
+			nav.Next = &articles[i+1]
+		}
+		break
+	}
+
+	return nav
+}
+
+// uniqueIDs ID去重
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"go-blog/internal/database"
+	"go-blog/internal/events"
 	"go-blog/internal/models"
 )
 
@@ -67,5 +68,10 @@ func UpdateSettings(settingsMap map[string]string) error {
 		}
 	}
 
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	events.Publish(events.SettingsUpdated, events.SettingsPayload{Settings: settingsMap})
+	return nil
 }
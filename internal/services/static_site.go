@@ -0,0 +1,512 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// siteTemplates 静态站点的页面模板
+// 前端是 React 单页应用，无法在服务端直接复用其组件，静态站点使用这里独立的 Go 模板，
+// 只保证内容一致（正文由 RenderMarkdown 按相同的 Markdown 语法渲染），页面布局和样式需分别维护
+//
+//go:embed templates/site/*.html
+var siteTemplates embed.FS
+
+// 静态站点清单文件，记录上次生成时各文章的更新时间
+const (
+	siteManifestFile    = ".generate-manifest.json"
+	siteManifestVersion = 1 // 渲染规则变化时递增，触发全部重建
+)
+
+// GenerateOptions 静态站点生成选项
+type GenerateOptions struct {
+	OutputDir string
+	Full      bool // 忽略上次生成的结果，全部重新渲染
+}
+
+// GenerateReport 静态站点生成结果
+type GenerateReport struct {
+	OutputDir   string `json:"output_dir"`
+	FullRebuild bool   `json:"full_rebuild"`
+	Rendered    int    `json:"rendered"`   // 重新渲染的文章数
+	Unchanged   int    `json:"unchanged"`  // 未变化而跳过的文章数
+	Removed     int    `json:"removed"`    // 已下线而删除的文章数
+	Pages       int    `json:"pages"`      // 各语言首页分页数之和
+	Languages   int    `json:"languages"`  // 语言数
+	Categories  int    `json:"categories"` // 分类页数
+	Tags        int    `json:"tags"`       // 标签页数
+}
+
+// siteManifest 上次生成的状态
+type siteManifest struct {
+	Version     int                `json:"version"`
+	Fingerprint string             `json:"fingerprint"` // 站点设置、分类标签和模板的摘要，变化时全部重建
+	Articles    map[uint]time.Time `json:"articles"`    // 文章及其各语言版本中最晚的更新时间
+	Languages   []string           `json:"languages"`   // 生成了首页的语言
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// GenerateSite 将已发布的文章、分类页、标签页、分页首页、RSS 和站点地图生成为静态文件
+// 默认语言的首页和 RSS 位于根目录，其他语言位于 /语言/ 下；分类和标签页包含所有语言的文章
+// 文章页按 UpdatedAt 增量生成（其他语言版本更新时也会重新生成，以更新 hreflang 链接），列表页、RSS 和站点地图每次重新生成
+func GenerateSite(opts GenerateOptions) (*GenerateReport, error) {
+	if opts.OutputDir == "" {
+		return nil, errors.New("未指定输出目录")
+	}
+
+	g, err := newSiteGenerator(opts.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := g.loadManifest()
+	full := opts.Full || manifest == nil || manifest.Version != siteManifestVersion || manifest.Fingerprint != g.fingerprint
+	report := &GenerateReport{OutputDir: opts.OutputDir, FullRebuild: full}
+	if full {
+		manifest = &siteManifest{Articles: make(map[uint]time.Time)}
+		if err := os.RemoveAll(g.path("article")); err != nil {
+			return nil, err
+		}
+	}
+
+	next := &siteManifest{
+		Version:     siteManifestVersion,
+		Fingerprint: g.fingerprint,
+		Articles:    make(map[uint]time.Time, len(g.articles)),
+	}
+
+	for i := range g.articles {
+		article := &g.articles[i]
+		updatedAt := g.groupUpdatedAt(article)
+		next.Articles[article.ID] = updatedAt
+
+		file := g.path("article", strconv.FormatUint(uint64(article.ID), 10), "index.html")
+		if previous, ok := manifest.Articles[article.ID]; ok && previous.Equal(updatedAt) && fileExists(file) {
+			report.Unchanged++
+			continue
+		}
+		if err := g.renderArticle(article, file); err != nil {
+			return nil, err
+		}
+		report.Rendered++
+	}
+
+	// 删除已下线或已删除的文章
+	for id := range manifest.Articles {
+		if _, ok := next.Articles[id]; ok {
+			continue
+		}
+		if err := os.RemoveAll(g.path("article", strconv.FormatUint(uint64(id), 10))); err != nil {
+			return nil, err
+		}
+		report.Removed++
+	}
+
+	// 列表页每次重新生成，先清理旧的分页目录和语言首页
+	dirs := []string{"page", "category", "tag"}
+	for _, language := range manifest.Languages {
+		if language != g.siteLang {
+			dirs = append(dirs, language)
+		}
+	}
+	for _, dir := range dirs {
+		if err := os.RemoveAll(g.path(dir)); err != nil {
+			return nil, err
+		}
+	}
+
+	var extraPaths []string
+	for _, language := range g.languages {
+		home := languageHomePath(language, g.siteLang)
+		pages, err := g.renderList("index", home, language, "", g.byLanguage[language], nil)
+		if err != nil {
+			return nil, err
+		}
+		report.Pages += pages
+		if home != "/" {
+			extraPaths = append(extraPaths, home)
+		}
+	}
+	report.Languages = len(g.languages)
+	next.Languages = g.languages
+
+	for _, category := range g.categories {
+		base := fmt.Sprintf("/category/%d/", category.ID)
+		if _, err := g.renderList("taxonomy", base, g.siteLang, category.Name, g.byCategory[category.ID], map[string]interface{}{
+			"Kind":                "分类",
+			"Name":                category.Name,
+			"TaxonomyDescription": category.Description,
+		}); err != nil {
+			return nil, err
+		}
+		extraPaths = append(extraPaths, base)
+		report.Categories++
+	}
+	for _, tag := range g.tags {
+		base := fmt.Sprintf("/tag/%d/", tag.ID)
+		if _, err := g.renderList("taxonomy", base, g.siteLang, tag.Name, g.byTag[tag.ID], map[string]interface{}{
+			"Kind": "标签",
+			"Name": tag.Name,
+		}); err != nil {
+			return nil, err
+		}
+		extraPaths = append(extraPaths, base)
+		report.Tags++
+	}
+
+	if err := g.renderFeeds(extraPaths); err != nil {
+		return nil, err
+	}
+
+	next.GeneratedAt = time.Now()
+	if err := g.saveManifest(next); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// siteGenerator 单次生成的数据
+type siteGenerator struct {
+	outputDir   string
+	settings    map[string]string
+	siteLang    string // 站点默认语言
+	pageSize    int
+	templates   map[string]*template.Template
+	fingerprint string
+
+	articles   []models.Article // 按首页顺序排列
+	categories []models.Category
+	tags       []models.Tag
+	byCategory map[uint][]models.Article
+	byTag      map[uint][]models.Article
+	languages  []string // 默认语言在前，其余按代码排序
+	byLanguage map[string][]models.Article
+	groups     map[uint][]*models.Article // 按翻译组归类的文章
+}
+
+// newSiteGenerator 加载模板和站点数据
+func newSiteGenerator(outputDir string) (*siteGenerator, error) {
+	settings, err := GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &siteGenerator{
+		outputDir:  outputDir,
+		settings:   settings,
+		siteLang:   siteLanguage(settings),
+		pageSize:   10,
+		templates:  make(map[string]*template.Template),
+		byCategory: make(map[uint][]models.Article),
+		byTag:      make(map[uint][]models.Article),
+		byLanguage: make(map[string][]models.Article),
+		groups:     make(map[uint][]*models.Article),
+	}
+	if size, err := strconv.Atoi(settings["posts_per_page"]); err == nil && size > 0 {
+		g.pageSize = size
+	}
+
+	funcs := template.FuncMap{
+		"formatDate":  func(t time.Time) string { return t.Format("2006-01-02") },
+		"publishedAt": articleDate,
+		"indent":      func(level int) int { return (level - 1) * 12 },
+	}
+	for _, page := range []string{"index", "article", "taxonomy"} {
+		tmpl, err := template.New(page).Funcs(funcs).ParseFS(siteTemplates, "templates/site/layout.html", "templates/site/"+page+".html")
+		if err != nil {
+			return nil, fmt.Errorf("加载站点模板失败: %w", err)
+		}
+		g.templates[page] = tmpl
+	}
+
+	if err := database.DB.Preload("Categories").Preload("Tags").
+		Where("status = ?", "published").
+		Order("is_pinned DESC, weight DESC, created_at DESC").
+		Find(&g.articles).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Order("id ASC").Find(&g.categories).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Order("id ASC").Find(&g.tags).Error; err != nil {
+		return nil, err
+	}
+
+	g.languages = []string{g.siteLang}
+	for i, article := range g.articles {
+		for _, category := range article.Categories {
+			g.byCategory[category.ID] = append(g.byCategory[category.ID], article)
+		}
+		for _, tag := range article.Tags {
+			g.byTag[tag.ID] = append(g.byTag[tag.ID], article)
+		}
+		if _, ok := g.byLanguage[article.Language]; !ok && article.Language != g.siteLang {
+			g.languages = append(g.languages, article.Language)
+		}
+		g.byLanguage[article.Language] = append(g.byLanguage[article.Language], article)
+		groupID := translationGroupID(&article)
+		g.groups[groupID] = append(g.groups[groupID], &g.articles[i])
+	}
+	sort.Strings(g.languages[1:])
+
+	fingerprint, err := g.computeFingerprint()
+	if err != nil {
+		return nil, err
+	}
+	g.fingerprint = fingerprint
+	return g, nil
+}
+
+// computeFingerprint 计算影响所有页面的数据摘要：站点设置、站点地址、分类标签名称和模板内容
+func (g *siteGenerator) computeFingerprint() (string, error) {
+	h := sha256.New()
+
+	settings, err := json.Marshal(g.settings)
+	if err != nil {
+		return "", err
+	}
+	h.Write(settings)
+	h.Write([]byte(siteURL()))
+	for _, category := range g.categories {
+		fmt.Fprintf(h, "\x00c%d=%s", category.ID, category.Name)
+	}
+	for _, tag := range g.tags {
+		fmt.Fprintf(h, "\x00t%d=%s", tag.ID, tag.Name)
+	}
+
+	err = fs.WalkDir(siteTemplates, "templates/site", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(siteTemplates, name)
+		if err != nil {
+			return err
+		}
+		h.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// siteLink 语言首页或文章的链接
+type siteLink struct {
+	Language string
+	Name     string
+	URL      string
+}
+
+// groupUpdatedAt 文章及其已发布的其他语言版本中最晚的更新时间
+func (g *siteGenerator) groupUpdatedAt(article *models.Article) time.Time {
+	updatedAt := article.UpdatedAt
+	for _, a := range g.groups[translationGroupID(article)] {
+		if a.UpdatedAt.After(updatedAt) {
+			updatedAt = a.UpdatedAt
+		}
+	}
+	return updatedAt
+}
+
+// pageData 所有页面共用的数据，站点名称和描述等使用该语言的设置
+func (g *siteGenerator) pageData(title, language string) map[string]interface{} {
+	settings := LocalizeSettings(g.settings, language)
+	name := settings["site_name"]
+	if name == "" {
+		name = siteName()
+	}
+	description := settings["seo_description"]
+	if description == "" {
+		description = settings["site_description"]
+	}
+
+	languages := make([]siteLink, 0, len(g.languages))
+	for _, code := range g.languages {
+		languages = append(languages, siteLink{Language: code, Name: languageName(code), URL: languageHomePath(code, g.siteLang)})
+	}
+
+	return map[string]interface{}{
+		"SiteName":     name,
+		"SiteSubtitle": settings["site_subtitle"],
+		"SiteURL":      siteURL(),
+		"Language":     language,
+		"HomePath":     languageHomePath(language, g.siteLang),
+		"Languages":    languages,
+		"Alternates":   []siteLink{},
+		"Title":        title,
+		"Description":  description,
+		"Keywords":     settings["seo_keywords"],
+		"ICP":          settings["icp_beian"],
+		"Year":         time.Now().Year(),
+	}
+}
+
+// renderArticle 渲染文章页，有其他语言版本时附带 hreflang 链接
+func (g *siteGenerator) renderArticle(article *models.Article, file string) error {
+	data := g.pageData(article.Title, article.Language)
+	data["Article"] = article
+	data["Content"] = RenderMarkdown(article.Content)
+	if article.Summary != "" {
+		data["Description"] = article.Summary
+	}
+
+	if group := g.groups[translationGroupID(article)]; len(group) > 1 {
+		alternates := make([]siteLink, 0, len(group))
+		var translations []siteLink
+		for _, a := range group {
+			link := siteLink{Language: a.Language, Name: languageName(a.Language), URL: articleURL(a.ID)}
+			alternates = append(alternates, link)
+			if a.ID != article.ID {
+				translations = append(translations, link)
+			}
+		}
+		data["Alternates"] = alternates
+		data["Translations"] = translations
+	}
+	return g.render("article", file, data)
+}
+
+// renderList 分页渲染文章列表，返回页数
+// 第一页位于 base，其余位于 base/page/N/
+func (g *siteGenerator) renderList(page, base, language, title string, articles []models.Article, extra map[string]interface{}) (int, error) {
+	totalPages := (len(articles) + g.pageSize - 1) / g.pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	pageURL := func(n int) string {
+		if n == 1 {
+			return base
+		}
+		return fmt.Sprintf("%spage/%d/", base, n)
+	}
+
+	for n := 1; n <= totalPages; n++ {
+		start := (n - 1) * g.pageSize
+		end := start + g.pageSize
+		if end > len(articles) {
+			end = len(articles)
+		}
+
+		data := g.pageData(title, language)
+		for k, v := range extra {
+			data[k] = v
+		}
+		data["Articles"] = articles[start:end]
+		data["Page"] = n
+		data["TotalPages"] = totalPages
+		data["PrevURL"] = ""
+		data["NextURL"] = ""
+		if n > 1 {
+			data["PrevURL"] = pageURL(n - 1)
+		}
+		if n < totalPages {
+			data["NextURL"] = pageURL(n + 1)
+		}
+
+		if err := g.render(page, g.path(filepath.FromSlash(pageURL(n)), "index.html"), data); err != nil {
+			return 0, err
+		}
+	}
+	return totalPages, nil
+}
+
+// renderFeeds 生成各语言的 RSS 和站点地图
+func (g *siteGenerator) renderFeeds(extraPaths []string) error {
+	for _, language := range g.languages {
+		latest := make([]models.Article, len(g.byLanguage[language]))
+		copy(latest, g.byLanguage[language])
+		sort.SliceStable(latest, func(i, j int) bool {
+			return articleDate(&latest[i]).After(articleDate(&latest[j]))
+		})
+		if len(latest) > feedSize {
+			latest = latest[:feedSize]
+		}
+
+		rss, err := renderRSS(latest, g.settings, language)
+		if err != nil {
+			return err
+		}
+		home := languageHomePath(language, g.siteLang)
+		if err := g.write(g.path(filepath.FromSlash(home), "feed.xml"), rss); err != nil {
+			return err
+		}
+	}
+
+	byID := make([]models.Article, len(g.articles))
+	copy(byID, g.articles)
+	sort.Slice(byID, func(i, j int) bool { return byID[i].ID < byID[j].ID })
+
+	sitemap, err := renderSitemap(sitemapEntries(byID, extraPaths))
+	if err != nil {
+		return err
+	}
+	return g.write(g.path("sitemap.xml"), sitemap)
+}
+
+// render 渲染模板到文件
+func (g *siteGenerator) render(page, file string, data map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := g.templates[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("渲染页面 %s 失败: %w", file, err)
+	}
+	return g.write(file, buf.Bytes())
+}
+
+// write 写入文件，自动创建目录
+func (g *siteGenerator) write(file string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0o644)
+}
+
+// path 输出目录下的路径
+func (g *siteGenerator) path(elem ...string) string {
+	return filepath.Join(append([]string{g.outputDir}, elem...)...)
+}
+
+// loadManifest 读取上次生成的清单，不存在或无法解析时返回 nil
+func (g *siteGenerator) loadManifest() *siteManifest {
+	content, err := os.ReadFile(g.path(siteManifestFile))
+	if err != nil {
+		return nil
+	}
+	var manifest siteManifest
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Articles == nil {
+		return nil
+	}
+	return &manifest
+}
+
+// saveManifest 保存本次生成的清单
+func (g *siteGenerator) saveManifest(manifest *siteManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.write(g.path(siteManifestFile), content)
+}
+
+// fileExists 文件是否存在
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
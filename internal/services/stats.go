@@ -0,0 +1,149 @@
+package services
+
+import (
+	"time"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+const (
+	dashboardTopLimit      = 5
+	dashboardTaxonomyLimit = 10
+	dashboardRecentLimit   = 5
+)
+
+// DashboardStats 后台概览统计
+type DashboardStats struct {
+	Articles       ArticleStats     `json:"articles"`
+	Comments       CommentStats     `json:"comments"`
+	Views          []TrafficPoint   `json:"views"` // 最近30天每日访问量
+	TopArticles    []TopViewArticle `json:"top_articles"`
+	TopTags        []TaxonomyCount  `json:"top_tags"`
+	TopCategories  []TaxonomyCount  `json:"top_categories"`
+	RecentComments []RecentComment  `json:"recent_comments"`
+}
+
+// ArticleStats 文章统计
+type ArticleStats struct {
+	Total     int64            `json:"total"`
+	ByStatus  map[string]int64 `json:"by_status"`
+	ViewTotal int64            `json:"view_total"`
+}
+
+// CommentStats 评论统计
+// 评论暂无审核状态，按全部和最近30天统计
+type CommentStats struct {
+	Total      int64 `json:"total"`
+	Last30Days int64 `json:"last_30_days"`
+}
+
+// TopViewArticle 浏览量最高的文章
+type TopViewArticle struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	ViewCount int64  `json:"view_count"`
+}
+
+// TaxonomyCount 标签/分类的文章数
+type TaxonomyCount struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ArticleCount int64  `json:"article_count"`
+}
+
+// RecentComment 最新评论
+type RecentComment struct {
+	ID           uint      `json:"id"`
+	ArticleID    uint      `json:"article_id"`
+	ArticleTitle string    `json:"article_title"`
+	Nickname     string    `json:"nickname"`
+	Content      string    `json:"content"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GetDashboardStats 获取后台概览统计，全部使用聚合查询
+func GetDashboardStats() (*DashboardStats, error) {
+	stats := &DashboardStats{}
+
+	// 文章按状态统计
+	var statusRows []struct {
+		Status string
+		Count  int64
+	}
+	if err := database.DB.Model(&models.Article{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&statusRows).Error; err != nil {
+		return nil, err
+	}
+	stats.Articles.ByStatus = make(map[string]int64, len(statusRows))
+	for _, row := range statusRows {
+		stats.Articles.ByStatus[row.Status] = row.Count
+		stats.Articles.Total += row.Count
+	}
+	if err := database.DB.Model(&models.Article{}).
+		Select("COALESCE(SUM(view_count), 0)").
+		Scan(&stats.Articles.ViewTotal).Error; err != nil {
+		return nil, err
+	}
+
+	// 评论统计
+	if err := database.DB.Model(&models.Comment{}).Count(&stats.Comments.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.Comment{}).
+		Where("created_at >= ?", time.Now().AddDate(0, 0, -30)).
+		Count(&stats.Comments.Last30Days).Error; err != nil {
+		return nil, err
+	}
+
+	// 最近30天访问量
+	views, err := GetTraffic(30)
+	if err != nil {
+		return nil, err
+	}
+	stats.Views = views
+
+	// 浏览量最高的文章
+	if err := database.DB.Model(&models.Article{}).
+		Select("id, title, view_count").
+		Where("status = ?", "published").
+		Order("view_count DESC").
+		Limit(dashboardTopLimit).
+		Scan(&stats.TopArticles).Error; err != nil {
+		return nil, err
+	}
+
+	// 文章最多的标签和分类
+	if err := database.DB.Table("tags").
+		Select("tags.id, tags.name, COUNT(article_tags.article_id) AS article_count").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Group("tags.id, tags.name").
+		Order("article_count DESC").
+		Limit(dashboardTaxonomyLimit).
+		Scan(&stats.TopTags).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Table("categories").
+		Select("categories.id, categories.name, COUNT(article_categories.article_id) AS article_count").
+		Joins("JOIN article_categories ON article_categories.category_id = categories.id").
+		Group("categories.id, categories.name").
+		Order("article_count DESC").
+		Limit(dashboardTaxonomyLimit).
+		Scan(&stats.TopCategories).Error; err != nil {
+		return nil, err
+	}
+
+	// 最新评论
+	if err := database.DB.Table("comments").
+		Select("comments.id, comments.article_id, articles.title AS article_title, comments.nickname, comments.content, comments.created_at").
+		Joins("LEFT JOIN articles ON articles.id = comments.article_id").
+		Order("comments.created_at DESC").
+		Limit(dashboardRecentLimit).
+		Scan(&stats.RecentComments).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
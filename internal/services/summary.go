@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+const (
+	// defaultSummaryLength 默认摘要长度（字符数）
+	defaultSummaryLength = 200
+	// maxSummaryLength 摘要字段最大长度
+	maxSummaryLength = 500
+)
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`<[^>]+>`)
+	listPrefixRegexp = regexp.MustCompile(`^([-*+]|\d+[.)])\s+`)
+	hrPattern        = regexp.MustCompile(`^([-*_]\s*){3,}$`)
+)
+
+// summaryAIService 用于生成AI摘要的服务，未设置时仅使用截取摘要
+var summaryAIService AIService
+
+// SetSummaryAIService 设置生成摘要使用的AI服务
+func SetSummaryAIService(s AIService) {
+	summaryAIService = s
+}
+
+// summaryMaxLength 获取配置的摘要长度
+func summaryMaxLength() int {
+	length := defaultSummaryLength
+	if config.AppConfig != nil && config.AppConfig.Summary.MaxLength > 0 {
+		length = config.AppConfig.Summary.MaxLength
+	}
+	if length > maxSummaryLength {
+		length = maxSummaryLength
+	}
+	return length
+}
+
+// aiSummaryEnabled 是否启用AI摘要
+func aiSummaryEnabled() bool {
+	return summaryAIService != nil && config.AppConfig != nil && config.AppConfig.Summary.Mode == "ai"
+}
+
+// GenerateSummary 从Markdown内容中截取摘要
+// 依次取正文段落，直到达到长度上限，并在句子边界处截断
+func GenerateSummary(content string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = defaultSummaryLength
+	}
+
+	var text strings.Builder
+	for _, paragraph := range markdownParagraphs(content) {
+		if text.Len() > 0 {
+			text.WriteString(" ")
+		}
+		text.WriteString(paragraph)
+		if utf8.RuneCountInString(text.String()) >= maxLength {
+			break
+		}
+	}
+
+	return truncateAtSentence(text.String(), maxLength)
+}
+
+// markdownParagraphs 将Markdown转换为纯文本段落，跳过标题、代码块、表格和分隔线
+func markdownParagraphs(content string) []string {
+	var paragraphs []string
+	var current []string
+
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, " "))
+			current = nil
+		}
+	}
+
+	for _, line := range scanMarkdown(content) {
+		if line.Code {
+			flush()
+			continue
+		}
+		trimmed := strings.TrimSpace(line.Text)
+
+		switch {
+		case trimmed == "":
+			flush()
+			continue
+		case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "|"), hrPattern.MatchString(trimmed):
+			flush()
+			continue
+		}
+
+		trimmed = strings.TrimSpace(strings.TrimLeft(trimmed, ">"))
+		trimmed = listPrefixRegexp.ReplaceAllString(trimmed, "")
+		trimmed = htmlTagPattern.ReplaceAllString(trimmed, "")
+		trimmed = stripInlineMarkdown(trimmed)
+		if trimmed != "" {
+			current = append(current, trimmed)
+		}
+	}
+	flush()
+
+	return paragraphs
+}
+
+// truncateAtSentence 在不超过长度上限的最后一个句子边界处截断
+// 找不到合适的句子边界时直接截断并追加省略号
+func truncateAtSentence(text string, maxLength int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxLength {
+		return string(runes)
+	}
+
+	runes = runes[:maxLength]
+	for i := len(runes) - 1; i >= maxLength/2; i-- {
+		switch runes[i] {
+		case '。', '！', '？', '；', '…':
+			return string(runes[:i+1])
+		case '.', '!', '?', ';':
+			// 英文标点需后接空白才视为句子结束，避免截断小数和缩写
+			if i+1 < len(runes) && runes[i+1] == ' ' {
+				return string(runes[:i+1])
+			}
+		}
+	}
+
+	// 英文内容尽量在单词边界处截断
+	cut := maxLength - 1
+	if i := strings.LastIndex(string(runes[:cut]), " "); i >= 0 {
+		if n := utf8.RuneCountInString(string(runes[:cut])[:i]); n >= maxLength/2 && !isCJK(runes[cut-1]) {
+			cut = n
+		}
+	}
+
+	return strings.TrimSpace(string(runes[:cut])) + "…"
+}
+
+// generateAISummary 调用AI为已发布文章生成摘要
+// 仅覆盖自动生成的摘要，作者手动填写的摘要保持不变
+func generateAISummary(articleID uint) {
+	if !aiSummaryEnabled() {
+		return
+	}
+
+	var article models.Article
+	if err := database.DB.First(&article, articleID).Error; err != nil {
+		return
+	}
+	if !article.SummaryAuto || article.Status != "published" {
+		return
+	}
+
+	maxLength := summaryMaxLength()
+	summary, err := summaryAIService.SummarizeArticle(context.Background(), &SummarizeArticleRequest{
+		Title:     article.Title,
+		Content:   article.Content,
+		MaxLength: maxLength,
+	})
+	if err != nil {
+		log.Printf("生成AI摘要失败（文章ID: %d）: %v", articleID, err)
+		return
+	}
+
+	summary = truncateAtSentence(strings.TrimSpace(summary), maxSummaryLength)
+	if summary == "" {
+		return
+	}
+
+	// 仅在摘要仍为自动生成时更新，避免覆盖期间作者的修改
+	database.DB.Model(&models.Article{}).
+		Where("id = ? AND summary_auto = ?", articleID, true).
+		UpdateColumn("summary", summary)
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TranslationJob 一次文章翻译任务
+type TranslationJob struct {
+	Original       *models.Article
+	TargetLanguage string
+	// Existing 已有的同语言译文草稿，保存时覆盖；为空时新建草稿
+	Existing *models.Article
+}
+
+// PrepareTranslation 校验原文和目标语言，查找已有的译文
+// 原文本身是译文时翻译它的原文；同语言的译文已发布时拒绝覆盖
+func PrepareTranslation(articleID uint, targetLanguage string) (*TranslationJob, error) {
+	if strings.TrimSpace(targetLanguage) == "" {
+		return nil, errors.New("目标语言不能为空")
+	}
+	target, err := NormalizeLanguage(targetLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	var original models.Article
+	if err := database.DB.Preload("Categories").Preload("Tags").First(&original, articleID).Error; err != nil {
+		return nil, errors.New("文章不存在")
+	}
+	if original.TranslationOfID != nil {
+		if err := database.DB.Preload("Categories").Preload("Tags").First(&original, *original.TranslationOfID).Error; err != nil {
+			return nil, errors.New("原文不存在")
+		}
+	}
+	if original.Language == target {
+		return nil, errors.New("目标语言与原文相同")
+	}
+
+	job := &TranslationJob{Original: &original, TargetLanguage: target}
+
+	var existing models.Article
+	err = database.DB.Where("translation_of_id = ? AND language = ?", original.ID, target).First(&existing).Error
+	switch {
+	case err == nil:
+		if existing.Status == "published" {
+			return nil, fmt.Errorf("该文章的%s译文已发布（ID %d），请先改为草稿", languageName(target), existing.ID)
+		}
+		job.Existing = &existing
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	return job, nil
+}
+
+// Translate 翻译标题并流式翻译正文，返回译文标题和正文
+func (job *TranslationJob) Translate(ctx context.Context, ai AIService, callback func(string) error) (string, string, error) {
+	title, err := ai.Translate(ctx, &TranslateRequest{
+		Content:        job.Original.Title,
+		SourceLanguage: job.Original.Language,
+		TargetLanguage: job.TargetLanguage,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	// 标题只取第一行，避免模型附带多余的内容
+	title = truncateString(strings.TrimSpace(strings.SplitN(title, "\n", 2)[0]), 255)
+
+	var content strings.Builder
+	err = ai.StreamTranslate(ctx, &TranslateRequest{
+		Content:        job.Original.Content,
+		SourceLanguage: job.Original.Language,
+		TargetLanguage: job.TargetLanguage,
+	}, func(chunk string) error {
+		content.WriteString(chunk)
+		return callback(chunk)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return title, strings.TrimSpace(content.String()), nil
+}
+
+// Save 将译文保存为草稿：已有译文草稿时覆盖其标题和正文，否则新建并沿用原文的分类和标签
+func (job *TranslationJob) Save(title, content string, authorID uint) (*models.Article, error) {
+	if title == "" || content == "" {
+		return nil, errors.New("译文为空")
+	}
+
+	if job.Existing != nil {
+		return UpdateArticle(job.Existing.ID, UpdateArticleRequest{
+			Title:   &title,
+			Content: &content,
+		}, job.Existing.AuthorID)
+	}
+
+	req := CreateArticleRequest{
+		Title:           title,
+		Content:         content,
+		Status:          "draft",
+		Language:        job.TargetLanguage,
+		TranslationOfID: &job.Original.ID,
+	}
+	for _, category := range job.Original.Categories {
+		req.CategoryIDs = append(req.CategoryIDs, category.ID)
+	}
+	for _, tag := range job.Original.Tags {
+		req.TagIDs = append(req.TagIDs, tag.ID)
+	}
+	return CreateArticle(req, authorID)
+}
+
+// ArticleTranslation 同一篇文章的某个语言版本
+type ArticleTranslation struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	Language string `json:"language"`
+	Status   string `json:"status"`
+	Original bool   `json:"original"` // 是否为原文
+}
+
+// translationGroupID 文章所属翻译组的ID，即原文的ID
+func translationGroupID(article *models.Article) uint {
+	if article.TranslationOfID != nil {
+		return *article.TranslationOfID
+	}
+	return article.ID
+}
+
+// GetArticleTranslations 获取同一翻译组中除自身外的其他语言版本，publishedOnly 时只返回已发布的
+func GetArticleTranslations(article *models.Article, publishedOnly bool) []ArticleTranslation {
+	groupID := translationGroupID(article)
+	db := database.DB.Model(&models.Article{}).
+		Where("(id = ? OR translation_of_id = ?) AND id <> ?", groupID, groupID, article.ID)
+	if publishedOnly {
+		db = db.Where("status = ?", "published")
+	}
+
+	var articles []models.Article
+	if err := db.Select("id", "title", "language", "status", "translation_of_id").Order("language").Find(&articles).Error; err != nil {
+		return []ArticleTranslation{}
+	}
+	translations := make([]ArticleTranslation, 0, len(articles))
+	for _, a := range articles {
+		translations = append(translations, ArticleTranslation{
+			ID:       a.ID,
+			Title:    a.Title,
+			Language: a.Language,
+			Status:   a.Status,
+			Original: a.TranslationOfID == nil,
+		})
+	}
+	return translations
+}
+
+// resolveTranslationOf 校验译文关联的原文，原文本身是译文时关联到它的原文
+func resolveTranslationOf(id *uint, language string) (*uint, error) {
+	if id == nil || *id == 0 {
+		return nil, nil
+	}
+
+	var original models.Article
+	if err := database.DB.Select("id", "language", "translation_of_id").First(&original, *id).Error; err != nil {
+		return nil, errors.New("原文不存在")
+	}
+	if original.TranslationOfID != nil {
+		if err := database.DB.Select("id", "language", "translation_of_id").First(&original, *original.TranslationOfID).Error; err != nil {
+			return nil, errors.New("原文不存在")
+		}
+	}
+	if err := checkTranslationLanguage(original.ID, language, 0); err != nil {
+		return nil, err
+	}
+	return &original.ID, nil
+}
+
+// checkTranslationLanguage 同一翻译组中每种语言只能有一个版本，excludeID 为正在修改的文章
+func checkTranslationLanguage(groupID uint, language string, excludeID uint) error {
+	var existing models.Article
+	err := database.DB.Select("id").
+		Where("(id = ? OR translation_of_id = ?) AND language = ? AND id <> ?", groupID, groupID, language, excludeID).
+		First(&existing).Error
+	if err == nil {
+		return fmt.Errorf("该文章已有%s版本（ID %d）", languageName(language), existing.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// detachTranslations 删除原文前，将最早的译文作为新的原文，其余译文改为关联到它
+func detachTranslations(tx *gorm.DB, article *models.Article) error {
+	if article.TranslationOfID != nil {
+		return nil
+	}
+
+	var next models.Article
+	err := tx.Select("id").Where("translation_of_id = ?", article.ID).Order("id ASC").First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Article{}).Where("id = ?", next.ID).Update("translation_of_id", nil).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Article{}).
+		Where("translation_of_id = ?", article.ID).
+		Update("translation_of_id", next.ID).Error
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultViewDedupWindow   = 30 * time.Minute
+	defaultViewFlushInterval = 30 * time.Second
+)
+
+// botUserAgentKeywords 常见爬虫和工具的 User-Agent 关键字
+var botUserAgentKeywords = []string{
+	"bot", "crawler", "spider", "slurp", "curl", "wget", "python-requests",
+	"go-http-client", "httpclient", "headless", "phantomjs", "lighthouse",
+	"facebookexternalhit", "embedly", "preview", "monitor", "scrapy",
+}
+
+// ViewCounter 内存浏览量计数器
+// 同一访客在时间窗口内重复访问只计一次，增量定期批量写入数据库
+type ViewCounter struct {
+	mu       sync.Mutex
+	pending  map[uint]int
+	seen     map[string]time.Time
+	window   time.Duration
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var viewCounter *ViewCounter
+
+// NewViewCounter 创建浏览量计数器
+func NewViewCounter(window, interval time.Duration) *ViewCounter {
+	return &ViewCounter{
+		pending:  make(map[uint]int),
+		seen:     make(map[string]time.Time),
+		window:   window,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// InitViewCounter 初始化全局浏览量计数器并启动定期写入
+func InitViewCounter() {
+	window := defaultViewDedupWindow
+	interval := defaultViewFlushInterval
+	cfg := config.AppConfig.Views
+	if cfg.DedupWindow > 0 {
+		window = time.Duration(cfg.DedupWindow) * time.Second
+	}
+	if cfg.FlushInterval > 0 {
+		interval = time.Duration(cfg.FlushInterval) * time.Second
+	}
+
+	viewCounter = NewViewCounter(window, interval)
+	go viewCounter.run()
+}
+
+// StopViewCounter 停止计数器，并将未写入的浏览量写入数据库
+func StopViewCounter() {
+	if viewCounter == nil {
+		return
+	}
+	close(viewCounter.stop)
+	<-viewCounter.done
+}
+
+// RecordArticleView 记录文章浏览，返回是否计数
+func RecordArticleView(articleID uint, fingerprint, userAgent string) bool {
+	if viewCounter == nil {
+		return false
+	}
+	return viewCounter.Record(articleID, fingerprint, userAgent)
+}
+
+// PendingArticleViews 获取尚未写入数据库的浏览量
+func PendingArticleViews(articleID uint) int {
+	if viewCounter == nil {
+		return 0
+	}
+	return viewCounter.Pending(articleID)
+}
+
+// IsBot 根据 User-Agent 判断是否为爬虫
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, keyword := range botUserAgentKeywords {
+		if strings.Contains(ua, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record 记录一次浏览，爬虫和时间窗口内的重复访问不计数
+func (vc *ViewCounter) Record(articleID uint, fingerprint, userAgent string) bool {
+	if IsBot(userAgent) {
+		return false
+	}
+
+	key := fmt.Sprintf("%d:%s", articleID, fingerprint)
+	now := time.Now()
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	if last, ok := vc.seen[key]; ok && now.Sub(last) < vc.window {
+		return false
+	}
+	vc.seen[key] = now
+	vc.pending[articleID]++
+	return true
+}
+
+// Pending 获取文章尚未写入的浏览量
+func (vc *ViewCounter) Pending(articleID uint) int {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.pending[articleID]
+}
+
+// run 定期写入浏览量，收到停止信号后做最后一次写入
+func (vc *ViewCounter) run() {
+	defer close(vc.done)
+
+	ticker := time.NewTicker(vc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			vc.Flush()
+		case <-vc.stop:
+			vc.Flush()
+			return
+		}
+	}
+}
+
+// Flush 将累计的浏览量增量写入数据库，并清理过期的访客记录
+func (vc *ViewCounter) Flush() {
+	vc.mu.Lock()
+	pending := vc.pending
+	vc.pending = make(map[uint]int)
+
+	now := time.Now()
+	for key, last := range vc.seen {
+		if now.Sub(last) >= vc.window {
+			delete(vc.seen, key)
+		}
+	}
+	vc.mu.Unlock()
+
+	for articleID, count := range pending {
+		// 使用原子自增，避免并发下丢失计数
+		err := database.DB.Model(&models.Article{}).Where("id = ?", articleID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", count)).Error
+		if err != nil {
+			log.Printf("写入浏览量失败（文章ID: %d）: %v", articleID, err)
+
+			// 写入失败时放回，等待下次写入
+			vc.mu.Lock()
+			vc.pending[articleID] += count
+			vc.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+)
+
+// Webhook 事件
+const (
+	WebhookEventArticlePublished = "article.published"
+	WebhookEventArticleUpdated   = "article.updated"
+	WebhookEventArticleDeleted   = "article.deleted"
+	WebhookEventCommentCreated   = "comment.created"
+)
+
+const (
+	defaultWebhookMaxAttempts  = 6
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookPollInterval = 15 * time.Second
+	webhookRetryBaseDelay      = 30 * time.Second
+	webhookRetryMaxDelay       = 2 * time.Hour
+	webhookBatchSize           = 20
+)
+
+// WebhookEvents 支持的事件
+var WebhookEvents = []string{
+	WebhookEventArticlePublished,
+	WebhookEventArticleUpdated,
+	WebhookEventArticleDeleted,
+	WebhookEventCommentCreated,
+}
+
+// WebhookRequest 创建/更新 Webhook 请求
+type WebhookRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookDeliveryListResponse 投递记录列表响应
+type WebhookDeliveryListResponse struct {
+	Total    int64                    `json:"total"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+	List     []models.WebhookDelivery `json:"list"`
+}
+
+// webhookPayload 投递内容
+type webhookPayload struct {
+	Event     string      `json:"event"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDispatcher Webhook 投递处理器
+type WebhookDispatcher struct {
+	client      *http.Client
+	maxAttempts int
+	interval    time.Duration
+	wake        chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+var webhookDispatcher *WebhookDispatcher
+
+// InitWebhooks 启动 Webhook 投递处理
+func InitWebhooks() {
+	cfg := config.AppConfig.Webhook
+
+	maxAttempts := defaultWebhookMaxAttempts
+	if cfg.MaxAttempts > 0 {
+		maxAttempts = cfg.MaxAttempts
+	}
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	interval := defaultWebhookPollInterval
+	if cfg.PollInterval > 0 {
+		interval = time.Duration(cfg.PollInterval) * time.Second
+	}
+
+	webhookDispatcher = &WebhookDispatcher{
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		interval:    interval,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go webhookDispatcher.run()
+}
+
+// StopWebhooks 停止投递处理，未完成的投递保留在队列中
+func StopWebhooks() {
+	if webhookDispatcher == nil {
+		return
+	}
+	close(webhookDispatcher.stop)
+	<-webhookDispatcher.done
+}
+
+// TriggerWebhook 为订阅了该事件的 Webhook 创建投递记录
+func TriggerWebhook(event string, data interface{}) {
+	if webhookDispatcher == nil {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := database.DB.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		log.Printf("读取Webhook失败: %v", err)
+		return
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		Event:     event,
+		Timestamp: time.Now().Unix(),
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("序列化Webhook内容失败: %v", err)
+		return
+	}
+
+	created := false
+	for _, webhook := range webhooks {
+		if !containsString(webhook.Events, event) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        "pending",
+			NextAttemptAt: time.Now(),
+		}
+		if err := database.DB.Create(&delivery).Error; err != nil {
+			log.Printf("创建Webhook投递记录失败: %v", err)
+			continue
+		}
+		created = true
+	}
+
+	if created {
+		webhookDispatcher.notify()
+	}
+}
+
+// GetWebhooks 获取 Webhook 列表
+func GetWebhooks() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := database.DB.Order("created_at DESC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook 创建 Webhook
+func CreateWebhook(req WebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhookRequest(&req); err != nil {
+		return nil, err
+	}
+
+	webhook := models.Webhook{
+		Name:   req.Name,
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+		Active: req.Active == nil || *req.Active,
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = randomToken(20)
+	}
+
+	if err := database.DB.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// UpdateWebhook 更新 Webhook，密钥为空时保留原密钥
+func UpdateWebhook(id uint, req WebhookRequest) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, id).Error; err != nil {
+		return nil, errors.New("Webhook不存在")
+	}
+	if err := validateWebhookRequest(&req); err != nil {
+		return nil, err
+	}
+
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.Events = req.Events
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := database.DB.Save(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook 删除 Webhook 及其投递记录
+func DeleteWebhook(id uint) error {
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, id).Error; err != nil {
+		return errors.New("Webhook不存在")
+	}
+
+	if err := database.DB.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return database.DB.Delete(&webhook).Error
+}
+
+// GetWebhookDeliveries 获取投递记录
+func GetWebhookDeliveries(webhookID uint, page, pageSize int) (*WebhookDeliveryListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	db := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	db.Count(&total)
+
+	var deliveries []models.WebhookDelivery
+	offset := (page - 1) * pageSize
+	if err := db.Order("id DESC").Limit(pageSize).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return &WebhookDeliveryListResponse{
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		List:     deliveries,
+	}, nil
+}
+
+// RedeliverWebhook 以原内容重新投递，生成新的投递记录
+func RedeliverWebhook(deliveryID uint) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := database.DB.First(&original, deliveryID).Error; err != nil {
+		return nil, errors.New("投递记录不存在")
+	}
+
+	var webhook models.Webhook
+	if err := database.DB.First(&webhook, original.WebhookID).Error; err != nil {
+		return nil, errors.New("Webhook不存在")
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	if webhookDispatcher != nil {
+		webhookDispatcher.notify()
+	}
+	return &delivery, nil
+}
+
+// webhookArticleData 文章事件内容
+func webhookArticleData(article *models.Article) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           article.ID,
+		"title":        article.Title,
+		"summary":      article.Summary,
+		"status":       article.Status,
+		"url":          articleURL(article.ID),
+		"author_id":    article.AuthorID,
+		"published_at": article.PublishedAt,
+		"updated_at":   article.UpdatedAt,
+	}
+}
+
+// webhookCommentData 评论事件内容（不包含评论者邮箱）
+func webhookCommentData(comment *models.Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         comment.ID,
+		"article_id": comment.ArticleID,
+		"parent_id":  comment.ParentID,
+		"nickname":   comment.Nickname,
+		"content":    comment.Content,
+		"url":        articleURL(comment.ArticleID),
+		"created_at": comment.CreatedAt,
+	}
+}
+
+// SignWebhookPayload 计算 HMAC-SHA256 签名
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notify 唤醒投递处理，立即处理新的投递
+func (d *WebhookDispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 处理投递队列
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.processQueue()
+		case <-d.wake:
+			d.processQueue()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// processQueue 投递到期的记录，失败时按指数退避重试
+func (d *WebhookDispatcher) processQueue() {
+	var deliveries []models.WebhookDelivery
+	if err := database.DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+		Order("id ASC").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		log.Printf("读取Webhook投递队列失败: %v", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+
+		var webhook models.Webhook
+		if err := database.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+			delivery.Status = "failed"
+			delivery.LastError = "Webhook不存在"
+			database.DB.Save(&delivery)
+			continue
+		}
+
+		d.deliver(&webhook, &delivery)
+		if err := database.DB.Save(&delivery).Error; err != nil {
+			log.Printf("更新Webhook投递记录失败（ID: %d）: %v", delivery.ID, err)
+		}
+	}
+}
+
+// deliver 发送一次投递请求并记录结果
+func (d *WebhookDispatcher) deliver(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	statusCode, body, err := d.send(webhook, delivery)
+	delivery.ResponseStatus = statusCode
+	delivery.ResponseBody = truncateString(body, 2000)
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		now := time.Now()
+		delivery.Status = "success"
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	if err != nil {
+		delivery.LastError = truncateString(err.Error(), 1000)
+	} else {
+		delivery.LastError = fmt.Sprintf("响应状态码: %d", statusCode)
+	}
+
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = "failed"
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(backoffDelay(delivery.Attempts, webhookRetryBaseDelay, webhookRetryMaxDelay))
+}
+
+// send 发送带签名的 POST 请求
+func (d *WebhookDispatcher) send(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	payload := []byte(delivery.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-blog-webhook")
+	req.Header.Set("X-Blog-Event", delivery.Event)
+	req.Header.Set("X-Blog-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	if webhook.Secret != "" {
+		req.Header.Set("X-Blog-Signature-256", SignWebhookPayload(webhook.Secret, payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, string(body), nil
+}
+
+// validateWebhookRequest 校验 Webhook 地址和事件
+func validateWebhookRequest(req *WebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("Webhook地址格式不正确")
+	}
+
+	if len(req.Events) == 0 {
+		req.Events = WebhookEvents
+	}
+	for _, event := range req.Events {
+		if !containsString(WebhookEvents, event) {
+			return fmt.Errorf("不支持的事件: %s", event)
+		}
+	}
+	return nil
+}
+
+// containsString 判断字符串是否在列表中
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
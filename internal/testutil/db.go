@@ -0,0 +1,55 @@
+// Package testutil 测试辅助函数，只在测试中引用
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"go-blog/internal/config"
+	"go-blog/internal/database"
+	"go-blog/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var dbCounter atomic.Int64
+
+// SetupDB 使用内存 SQLite 数据库替换 database.DB 并完成迁移，测试结束后恢复
+// 同时设置空的 config.AppConfig，需要时由测试修改
+func SetupDB(t testing.TB) {
+	t.Helper()
+
+	// 每个测试使用独立的内存数据库
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", dbCounter.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+
+	previousDB, previousConfig := database.DB, config.AppConfig
+	database.DB = db
+	config.AppConfig = &config.Config{}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB, config.AppConfig = previousDB, previousConfig
+	})
+
+	if err := database.AutoMigrate(); err != nil {
+		t.Fatalf("迁移测试数据库失败: %v", err)
+	}
+}
+
+// CreateUser 创建作者用户
+func CreateUser(t testing.TB, username string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Password: "x", Email: username + "@example.com", Role: "author"}
+	if err := database.DB.Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return user
+}
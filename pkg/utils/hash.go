@@ -1,6 +1,13 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword 密码哈希
 func HashPassword(password string) (string, error) {
@@ -13,3 +20,16 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// Fingerprint 根据访客信息（如IP、User-Agent）生成匿名指纹，不保存原始信息
+func Fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// KeyedFingerprint 使用服务端密钥生成匿名指纹，需要持久保存时使用，无法通过枚举IP反推
+func KeyedFingerprint(key string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+	blankLinesPattern  = regexp.MustCompile(`\n{3,}`)
+	nestedListPattern  = regexp.MustCompile(`\n\n((?:[-*+]|\d+\.) )`)
+	markdownEscapeChar = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+)
+
+// HTMLToMarkdown 将HTML转换为Markdown
+// 支持标题、段落、强调、链接、图片、列表、引用、代码块和表格，其余标签仅保留文本
+func HTMLToMarkdown(content string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	for _, n := range nodes {
+		buf.WriteString(renderMarkdown(n))
+	}
+
+	// 清理空白行
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		}
+	}
+	result := blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(result), nil
+}
+
+// renderMarkdown 转换单个节点
+func renderMarkdown(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := markdownEscapeChar.Replace(whitespacePattern.ReplaceAllString(n.Data, " "))
+		// 换行后的缩进没有意义
+		if prev := n.PrevSibling; prev != nil && prev.Type == html.ElementNode && prev.DataAtom == atom.Br {
+			text = strings.TrimLeft(text, " ")
+		}
+		return text
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript:
+		return ""
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return markdownBlock(strings.Repeat("#", level) + " " + strings.TrimSpace(renderChildren(n)))
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Figcaption, atom.Header, atom.Footer, atom.Main:
+		return markdownBlock(renderChildren(n))
+	case atom.Br:
+		return "  \n"
+	case atom.Hr:
+		return markdownBlock("---")
+	case atom.Strong, atom.B:
+		return markdownWrap(renderChildren(n), "**")
+	case atom.Em, atom.I:
+		return markdownWrap(renderChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return markdownWrap(renderChildren(n), "~~")
+	case atom.Code:
+		return inlineCode(textContent(n))
+	case atom.Pre:
+		return markdownBlock("```" + codeLanguage(n) + "\n" + strings.TrimRight(textContent(n), "\n") + "\n```")
+	case atom.A:
+		text := strings.TrimSpace(renderChildren(n))
+		href := attr(n, "href")
+		if href == "" {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		if title := attr(n, "title"); title != "" {
+			return "[" + text + "](" + href + " \"" + strings.ReplaceAll(title, `"`, `\"`) + "\")"
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return "![" + markdownEscapeChar.Replace(attr(n, "alt")) + "](" + src + ")"
+	case atom.Ul, atom.Ol:
+		return markdownBlock(renderList(n))
+	case atom.Blockquote:
+		content := blankLinesPattern.ReplaceAllString(strings.TrimSpace(renderChildren(n)), "\n\n")
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				lines[i] = ">"
+			} else {
+				lines[i] = "> " + line
+			}
+		}
+		return markdownBlock(strings.Join(lines, "\n"))
+	case atom.Table:
+		return markdownBlock(renderTable(n))
+	}
+
+	return renderChildren(n)
+}
+
+// renderChildren 转换全部子节点
+func renderChildren(n *html.Node) string {
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		buf.WriteString(renderMarkdown(c))
+	}
+	return buf.String()
+}
+
+// renderList 转换列表，列表项中的多行内容按标记宽度缩进
+func renderList(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	index := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil && ordered {
+		index = start
+	}
+
+	var items []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+
+		content := strings.TrimSpace(blankLinesPattern.ReplaceAllString(renderChildren(c), "\n\n"))
+		// 嵌套列表紧跟在列表项文本之后
+		content = nestedListPattern.ReplaceAllString(content, "\n$1")
+		lines := strings.Split(content, "\n")
+		indent := strings.Repeat(" ", len(marker))
+		for i := range lines {
+			if i == 0 {
+				lines[i] = marker + lines[i]
+			} else if strings.TrimSpace(lines[i]) != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// renderTable 转换为GFM表格，第一行作为表头
+func renderTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom != atom.Tr {
+				walk(c)
+				continue
+			}
+			var cells []string
+			for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					text := strings.TrimSpace(whitespacePattern.ReplaceAllString(renderChildren(cell), " "))
+					cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+				}
+			}
+			rows = append(rows, cells)
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	var lines []string
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownBlock 块级元素前后空行
+func markdownBlock(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	return "\n\n" + s + "\n\n"
+}
+
+// markdownWrap 为行内内容添加标记，标记放在空白之内
+func markdownWrap(s, mark string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + mark + trimmed + mark + s[start+len(trimmed):]
+}
+
+// inlineCode 生成行内代码，内容包含反引号时使用更长的分隔符
+func inlineCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// codeLanguage 从 pre 或其中 code 的 class 中识别代码语言
+func codeLanguage(n *html.Node) string {
+	candidates := []*html.Node{n}
+	if c := n.FirstChild; c != nil && c.Type == html.ElementNode && c.DataAtom == atom.Code {
+		candidates = append(candidates, c)
+	}
+	for _, node := range candidates {
+		for _, class := range strings.Fields(attr(node, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if strings.HasPrefix(class, prefix) {
+					return strings.TrimPrefix(class, prefix)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// textContent 获取节点的原始文本
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var buf strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Br {
+			buf.WriteString("\n")
+			continue
+		}
+		buf.WriteString(textContent(c))
+	}
+	return buf.String()
+}
+
+// attr 获取属性值
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}